@@ -0,0 +1,126 @@
+package radosgwapi
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/url"
+	"strconv"
+)
+
+// UserConfig holds the parameters of CreateUser and ModifyUser. Empty
+// strings and nil pointers are not sent, so ModifyUser only changes the
+// fields that are set.
+type UserConfig struct {
+	UID         string
+	Tenant      string
+	DisplayName string
+	Email       string
+	KeyType     string
+	AccessKey   string
+	SecretKey   string
+	UserCaps    string
+	GenerateKey *bool
+	MaxBuckets  *int
+	Suspended   *bool
+}
+
+func (userCfg *UserConfig) args() url.Values {
+	args := url.Values{}
+	args.Add("uid", userCfg.UID)
+
+	addString := func(key, value string) {
+		if "" != value {
+			args.Add(key, value)
+		}
+	}
+	addString("tenant", userCfg.Tenant)
+	addString("display-name", userCfg.DisplayName)
+	addString("email", userCfg.Email)
+	addString("key-type", userCfg.KeyType)
+	addString("access-key", userCfg.AccessKey)
+	addString("secret-key", userCfg.SecretKey)
+	addString("user-caps", userCfg.UserCaps)
+
+	if nil != userCfg.GenerateKey {
+		args.Add("generate-key", strconv.FormatBool(*userCfg.GenerateKey))
+	}
+	if nil != userCfg.MaxBuckets {
+		args.Add("max-buckets", strconv.Itoa(*userCfg.MaxBuckets))
+	}
+	if nil != userCfg.Suspended {
+		args.Add("suspended", strconv.FormatBool(*userCfg.Suspended))
+	}
+
+	return args
+}
+
+// adminRequest issues an admin ops request and decodes the JSON response
+// into result, unless result is nil.
+func (conn *Connection) adminRequest(ctx context.Context, method, router string, args url.Values, reqBody io.Reader, result interface{}) error {
+	_, _, body, err := conn.RequestWithContext(ctx, method, router, args, reqBody)
+	if nil != err {
+		return err
+	}
+
+	if nil == result || 0 == len(body) {
+		return nil
+	}
+
+	return json.Unmarshal(body, result)
+}
+
+func (conn *Connection) GetUserInfo(uid string, stats bool) (userInfo *UserInfo, err error) {
+	return conn.GetUserInfoWithContext(context.Background(), uid, stats)
+}
+
+// GetUserInfoWithContext is GetUser with the response decoded. With stats set,
+// UserInfo.Stats is filled in as well.
+func (conn *Connection) GetUserInfoWithContext(ctx context.Context, uid string, stats bool) (userInfo *UserInfo, err error) {
+	args := url.Values{}
+	args.Add("uid", uid)
+	if stats {
+		args.Add("stats", "true")
+	}
+
+	userInfo = &UserInfo{}
+	err = conn.adminRequest(ctx, "GET", "/admin/user", args, nil, userInfo)
+	return
+}
+
+func (conn *Connection) CreateUser(userCfg *UserConfig) (userInfo *UserInfo, err error) {
+	return conn.CreateUserWithContext(context.Background(), userCfg)
+}
+
+func (conn *Connection) CreateUserWithContext(ctx context.Context, userCfg *UserConfig) (userInfo *UserInfo, err error) {
+	userInfo = &UserInfo{}
+	err = conn.adminRequest(ctx, "PUT", "/admin/user", userCfg.args(), nil, userInfo)
+	return
+}
+
+func (conn *Connection) ModifyUser(userCfg *UserConfig) (userInfo *UserInfo, err error) {
+	return conn.ModifyUserWithContext(context.Background(), userCfg)
+}
+
+func (conn *Connection) ModifyUserWithContext(ctx context.Context, userCfg *UserConfig) (userInfo *UserInfo, err error) {
+	userInfo = &UserInfo{}
+	err = conn.adminRequest(ctx, "POST", "/admin/user", userCfg.args(), nil, userInfo)
+	return
+}
+
+func (conn *Connection) RemoveUser(uid string, purgeData bool) (err error) {
+	return conn.RemoveUserWithContext(context.Background(), uid, purgeData)
+}
+
+// RemoveUserWithContext removes the user. With purgeData set, the user's
+// buckets and objects are deleted too; otherwise removal fails while the
+// user still owns buckets.
+func (conn *Connection) RemoveUserWithContext(ctx context.Context, uid string, purgeData bool) (err error) {
+	args := url.Values{}
+	args.Add("uid", uid)
+	if purgeData {
+		args.Add("purge-data", "true")
+	}
+
+	return conn.adminRequest(ctx, "DELETE", "/admin/user", args, nil, nil)
+}
@@ -0,0 +1,153 @@
+package radosgwapi
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"io"
+	"net/url"
+	"strconv"
+)
+
+func (conn *Connection) GetBucketStats(bucketName string) (bucketStats *BucketStats, err error) {
+	return conn.GetBucketStatsWithContext(context.Background(), bucketName)
+}
+
+// GetBucketStatsWithContext returns the bucket's owner, ids, placement,
+// per-category usage and quota.
+func (conn *Connection) GetBucketStatsWithContext(ctx context.Context, bucketName string) (bucketStats *BucketStats, err error) {
+	args := url.Values{}
+	args.Add("bucket", bucketName)
+	args.Add("stats", "true")
+
+	bucketStats = &BucketStats{}
+	err = conn.adminRequest(ctx, "GET", "/admin/bucket", args, nil, bucketStats)
+	return
+}
+
+func (conn *Connection) ListUserBuckets(uid string) (buckets []string, err error) {
+	return conn.ListUserBucketsWithContext(context.Background(), uid)
+}
+
+// ListUserBucketsWithContext returns the names of the buckets owned by uid,
+// or of every bucket when uid is empty.
+func (conn *Connection) ListUserBucketsWithContext(ctx context.Context, uid string) (buckets []string, err error) {
+	args := url.Values{}
+	if "" != uid {
+		args.Add("uid", uid)
+	}
+
+	err = conn.adminRequest(ctx, "GET", "/admin/bucket", args, nil, &buckets)
+	return
+}
+
+func (conn *Connection) ListUserBucketStats(uid string) (bucketStats []BucketStats, err error) {
+	return conn.ListUserBucketStatsWithContext(context.Background(), uid)
+}
+
+// ListUserBucketStatsWithContext is ListUserBuckets with the stats of every
+// bucket.
+func (conn *Connection) ListUserBucketStatsWithContext(ctx context.Context, uid string) (bucketStats []BucketStats, err error) {
+	args := url.Values{}
+	if "" != uid {
+		args.Add("uid", uid)
+	}
+	args.Add("stats", "true")
+
+	err = conn.adminRequest(ctx, "GET", "/admin/bucket", args, nil, &bucketStats)
+	return
+}
+
+func (conn *Connection) LinkBucket(bucketName, bucketId, uid string) (err error) {
+	return conn.LinkBucketWithContext(context.Background(), bucketName, bucketId, uid)
+}
+
+// LinkBucketWithContext makes uid the owner of the bucket. To move a bucket
+// between users, unlink it from the current owner first; bucketId is
+// BucketStats.Id and may be empty on RGW versions that do not require it.
+func (conn *Connection) LinkBucketWithContext(ctx context.Context, bucketName, bucketId, uid string) (err error) {
+	args := url.Values{}
+	args.Add("bucket", bucketName)
+	args.Add("uid", uid)
+	if "" != bucketId {
+		args.Add("bucket-id", bucketId)
+	}
+
+	return conn.adminRequest(ctx, "PUT", "/admin/bucket", args, nil, nil)
+}
+
+func (conn *Connection) UnlinkBucket(bucketName, uid string) (err error) {
+	return conn.UnlinkBucketWithContext(context.Background(), bucketName, uid)
+}
+
+// UnlinkBucketWithContext removes the bucket from uid's bucket list without
+// touching its data.
+func (conn *Connection) UnlinkBucketWithContext(ctx context.Context, bucketName, uid string) (err error) {
+	args := url.Values{}
+	args.Add("bucket", bucketName)
+	args.Add("uid", uid)
+
+	return conn.adminRequest(ctx, "POST", "/admin/bucket", args, nil, nil)
+}
+
+func (conn *Connection) CheckBucketIndex(bucketName string, checkObjects, fix bool) (indexCheck *BucketIndexCheck, err error) {
+	return conn.CheckBucketIndexWithContext(context.Background(), bucketName, checkObjects, fix)
+}
+
+// CheckBucketIndexWithContext compares the bucket index header with the
+// stats recalculated from its entries, and repairs the index if fix is set.
+func (conn *Connection) CheckBucketIndexWithContext(ctx context.Context, bucketName string, checkObjects, fix bool) (indexCheck *BucketIndexCheck, err error) {
+	args := url.Values{}
+	args.Add("index", "")
+	args.Add("bucket", bucketName)
+	args.Add("check-objects", strconv.FormatBool(checkObjects))
+	args.Add("fix", strconv.FormatBool(fix))
+
+	_, _, body, err := conn.RequestWithContext(ctx, "GET", "/admin/bucket", args, nil)
+	if nil != err {
+		return
+	}
+
+	// RGW writes the invalid multipart entries and the check result as
+	// consecutive top-level JSON values rather than a single document.
+	indexCheck = &BucketIndexCheck{}
+	decoder := json.NewDecoder(bytes.NewReader(body))
+	for {
+		var value json.RawMessage
+		err = decoder.Decode(&value)
+		if io.EOF == err {
+			return indexCheck, nil
+		}
+		if nil != err {
+			return
+		}
+
+		if '[' == value[0] {
+			err = json.Unmarshal(value, &indexCheck.InvalidMultipartEntries)
+		} else {
+			err = json.Unmarshal(value, indexCheck)
+			if nil == err {
+				err = json.Unmarshal(value, &struct {
+					CheckResult *BucketIndexCheck `json:"check_result"`
+				}{indexCheck})
+			}
+		}
+		if nil != err {
+			return
+		}
+	}
+}
+
+func (conn *Connection) RemoveBucket(bucketName string, purgeObjects bool) (err error) {
+	return conn.RemoveBucketWithContext(context.Background(), bucketName, purgeObjects)
+}
+
+// RemoveBucketWithContext removes the bucket through the admin API. With
+// purgeObjects set, a non-empty bucket is removed together with its objects.
+func (conn *Connection) RemoveBucketWithContext(ctx context.Context, bucketName string, purgeObjects bool) (err error) {
+	args := url.Values{}
+	args.Add("bucket", bucketName)
+	args.Add("purge-objects", strconv.FormatBool(purgeObjects))
+
+	return conn.adminRequest(ctx, "DELETE", "/admin/bucket", args, nil, nil)
+}
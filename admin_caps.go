@@ -0,0 +1,193 @@
+package radosgwapi
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"sort"
+	"strings"
+)
+
+// Capability types.
+const (
+	CapUsers    = "users"
+	CapBuckets  = "buckets"
+	CapMetadata = "metadata"
+	CapUsage    = "usage"
+	CapZone     = "zone"
+	CapInfo     = "info"
+)
+
+// Capability permissions. PermAll grants both read and write.
+const (
+	PermRead  = "read"
+	PermWrite = "write"
+	PermAll   = "*"
+)
+
+// capTypes are the types RGW accepts, including the ones without a constant.
+var capTypes = map[string]bool{
+	CapUsers: true, CapBuckets: true, CapMetadata: true, CapUsage: true, CapZone: true, CapInfo: true,
+	"bilog": true, "mdlog": true, "datalog": true, "roles": true, "user-policy": true,
+	"oidc-provider": true, "ratelimit": true, "amz-cache": true,
+}
+
+const (
+	capRead = 1 << iota
+	capWrite
+)
+
+func capPermBits(perm string) (bits int, err error) {
+	for _, p := range strings.Split(perm, ",") {
+		switch strings.TrimSpace(p) {
+		case PermRead:
+			bits |= capRead
+		case PermWrite:
+			bits |= capWrite
+		case PermAll:
+			bits |= capRead | capWrite
+		default:
+			return 0, fmt.Errorf("radosgwapi: invalid cap permission %q", perm)
+		}
+	}
+	return
+}
+
+func capPermString(bits int) string {
+	switch bits {
+	case capRead:
+		return PermRead
+	case capWrite:
+		return PermWrite
+	}
+	return PermAll
+}
+
+// Validate checks the type against the capability types RGW knows and the
+// permission against read, write and *.
+func (userCap UserCap) Validate() error {
+	if !capTypes[userCap.Type] {
+		return fmt.Errorf("radosgwapi: invalid cap type %q", userCap.Type)
+	}
+	_, err := capPermBits(userCap.Perm)
+	return err
+}
+
+// ParseCaps parses the radosgw-admin form "users=read;buckets=*". Permissions
+// are normalized, so "read,write" becomes "*".
+func ParseCaps(s string) (caps []UserCap, err error) {
+	for _, item := range strings.Split(s, ";") {
+		if "" == strings.TrimSpace(item) {
+			continue
+		}
+
+		kv := strings.SplitN(item, "=", 2)
+		if 2 != len(kv) {
+			return nil, fmt.Errorf("radosgwapi: invalid cap %q", item)
+		}
+
+		userCap := UserCap{Type: strings.TrimSpace(kv[0]), Perm: strings.TrimSpace(kv[1])}
+		if err = userCap.Validate(); nil != err {
+			return nil, err
+		}
+		bits, _ := capPermBits(userCap.Perm)
+		userCap.Perm = capPermString(bits)
+		caps = append(caps, userCap)
+	}
+	return
+}
+
+// FormatCaps is the inverse of ParseCaps, with the types sorted.
+func FormatCaps(caps []UserCap) string {
+	items := make([]string, 0, len(caps))
+	for _, userCap := range caps {
+		items = append(items, userCap.Type+"="+userCap.Perm)
+	}
+	sort.Strings(items)
+	return strings.Join(items, ";")
+}
+
+func capBits(caps []UserCap) map[string]int {
+	bits := map[string]int{}
+	for _, userCap := range caps {
+		b, _ := capPermBits(userCap.Perm)
+		bits[userCap.Type] |= b
+	}
+	return bits
+}
+
+// DiffCaps returns the caps to add and to remove to turn current into
+// desired, suitable for AddUserCaps and RemoveUserCaps.
+func DiffCaps(current, desired []UserCap) (add, remove []UserCap) {
+	currentBits, desiredBits := capBits(current), capBits(desired)
+
+	for capType, want := range desiredBits {
+		if missing := want &^ currentBits[capType]; 0 != missing {
+			add = append(add, UserCap{Type: capType, Perm: capPermString(missing)})
+		}
+	}
+	for capType, have := range currentBits {
+		if extra := have &^ desiredBits[capType]; 0 != extra {
+			remove = append(remove, UserCap{Type: capType, Perm: capPermString(extra)})
+		}
+	}
+
+	sort.Slice(add, func(i, j int) bool { return add[i].Type < add[j].Type })
+	sort.Slice(remove, func(i, j int) bool { return remove[i].Type < remove[j].Type })
+	return
+}
+
+// HasCap reports whether the user's caps grant perm on capType.
+func (userInfo *UserInfo) HasCap(capType, perm string) bool {
+	want, err := capPermBits(perm)
+	if nil != err {
+		return false
+	}
+	return want == want&capBits(userInfo.Caps)[capType]
+}
+
+func (conn *Connection) AddUserCaps(uid string, caps []UserCap) (userCaps []UserCap, err error) {
+	return conn.AddUserCapsWithContext(context.Background(), uid, caps)
+}
+
+// AddUserCapsWithContext grants caps to the user and returns the resulting
+// caps of the user.
+func (conn *Connection) AddUserCapsWithContext(ctx context.Context, uid string, caps []UserCap) (userCaps []UserCap, err error) {
+	args, err := userCapsArgs(uid, caps)
+	if nil != err {
+		return
+	}
+
+	err = conn.adminRequest(ctx, "PUT", "/admin/user", args, nil, &userCaps)
+	return
+}
+
+func (conn *Connection) RemoveUserCaps(uid string, caps []UserCap) (userCaps []UserCap, err error) {
+	return conn.RemoveUserCapsWithContext(context.Background(), uid, caps)
+}
+
+// RemoveUserCapsWithContext revokes caps from the user and returns the
+// remaining caps of the user.
+func (conn *Connection) RemoveUserCapsWithContext(ctx context.Context, uid string, caps []UserCap) (userCaps []UserCap, err error) {
+	args, err := userCapsArgs(uid, caps)
+	if nil != err {
+		return
+	}
+
+	err = conn.adminRequest(ctx, "DELETE", "/admin/user", args, nil, &userCaps)
+	return
+}
+
+func userCapsArgs(uid string, caps []UserCap) (args url.Values, err error) {
+	for _, userCap := range caps {
+		if err = userCap.Validate(); nil != err {
+			return
+		}
+	}
+
+	args = url.Values{}
+	args.Add("caps", "")
+	args.Add("uid", uid)
+	args.Add("user-caps", FormatCaps(caps))
+	return
+}
@@ -0,0 +1,145 @@
+package radosgwapi
+
+import (
+	"context"
+	"net/url"
+	"strconv"
+)
+
+// Subuser access levels.
+const (
+	AccessRead      = "read"
+	AccessWrite     = "write"
+	AccessReadWrite = "readwrite"
+	AccessFull      = "full"
+)
+
+// Key types.
+const (
+	KeyTypeS3    = "s3"
+	KeyTypeSwift = "swift"
+)
+
+// SubuserConfig holds the parameters of CreateSubuser and ModifySubuser.
+// Subuser is the subuser name, with or without the "uid:" prefix.
+type SubuserConfig struct {
+	UID         string
+	Subuser     string
+	SecretKey   string
+	KeyType     string
+	Access      string
+	GenerateKey *bool
+}
+
+func (subuserCfg *SubuserConfig) args() url.Values {
+	args := url.Values{}
+	args.Add("uid", subuserCfg.UID)
+	args.Add("subuser", subuserCfg.Subuser)
+
+	if "" != subuserCfg.SecretKey {
+		args.Add("secret-key", subuserCfg.SecretKey)
+	}
+	if "" != subuserCfg.KeyType {
+		args.Add("key-type", subuserCfg.KeyType)
+	}
+	if "" != subuserCfg.Access {
+		args.Add("access", subuserCfg.Access)
+	}
+	if nil != subuserCfg.GenerateKey {
+		args.Add("generate-secret", strconv.FormatBool(*subuserCfg.GenerateKey))
+	}
+
+	return args
+}
+
+// KeyConfig holds the parameters of CreateKey and RemoveKey. Set Subuser to
+// act on a subuser's key, and AccessKey/SecretKey to specify the key instead
+// of having one generated.
+type KeyConfig struct {
+	UID         string
+	Subuser     string
+	KeyType     string
+	AccessKey   string
+	SecretKey   string
+	GenerateKey *bool
+}
+
+func (keyCfg *KeyConfig) args() url.Values {
+	args := url.Values{}
+	args.Add("key", "")
+	args.Add("uid", keyCfg.UID)
+
+	if "" != keyCfg.Subuser {
+		args.Add("subuser", keyCfg.Subuser)
+	}
+	if "" != keyCfg.KeyType {
+		args.Add("key-type", keyCfg.KeyType)
+	}
+	if "" != keyCfg.AccessKey {
+		args.Add("access-key", keyCfg.AccessKey)
+	}
+	if "" != keyCfg.SecretKey {
+		args.Add("secret-key", keyCfg.SecretKey)
+	}
+	if nil != keyCfg.GenerateKey {
+		args.Add("generate-key", strconv.FormatBool(*keyCfg.GenerateKey))
+	}
+
+	return args
+}
+
+func (conn *Connection) CreateSubuser(subuserCfg *SubuserConfig) (subusers []SubuserInfo, err error) {
+	return conn.CreateSubuserWithContext(context.Background(), subuserCfg)
+}
+
+// CreateSubuserWithContext creates the subuser and returns all subusers of
+// the user.
+func (conn *Connection) CreateSubuserWithContext(ctx context.Context, subuserCfg *SubuserConfig) (subusers []SubuserInfo, err error) {
+	err = conn.adminRequest(ctx, "PUT", "/admin/user", subuserCfg.args(), nil, &subusers)
+	return
+}
+
+func (conn *Connection) ModifySubuser(subuserCfg *SubuserConfig) (subusers []SubuserInfo, err error) {
+	return conn.ModifySubuserWithContext(context.Background(), subuserCfg)
+}
+
+func (conn *Connection) ModifySubuserWithContext(ctx context.Context, subuserCfg *SubuserConfig) (subusers []SubuserInfo, err error) {
+	err = conn.adminRequest(ctx, "POST", "/admin/user", subuserCfg.args(), nil, &subusers)
+	return
+}
+
+func (conn *Connection) RemoveSubuser(uid, subuser string, purgeKeys bool) (err error) {
+	return conn.RemoveSubuserWithContext(context.Background(), uid, subuser, purgeKeys)
+}
+
+// RemoveSubuserWithContext removes the subuser, and its keys too when
+// purgeKeys is set.
+func (conn *Connection) RemoveSubuserWithContext(ctx context.Context, uid, subuser string, purgeKeys bool) (err error) {
+	args := url.Values{}
+	args.Add("uid", uid)
+	args.Add("subuser", subuser)
+	args.Add("purge-keys", strconv.FormatBool(purgeKeys))
+
+	return conn.adminRequest(ctx, "DELETE", "/admin/user", args, nil, nil)
+}
+
+func (conn *Connection) CreateKey(keyCfg *KeyConfig) (keys []UserKey, err error) {
+	return conn.CreateKeyWithContext(context.Background(), keyCfg)
+}
+
+// CreateKeyWithContext generates or sets a key and returns all keys of the
+// user. Swift keys are reported with an empty AccessKey.
+func (conn *Connection) CreateKeyWithContext(ctx context.Context, keyCfg *KeyConfig) (keys []UserKey, err error) {
+	err = conn.adminRequest(ctx, "PUT", "/admin/user", keyCfg.args(), nil, &keys)
+	return
+}
+
+func (conn *Connection) RemoveKey(keyCfg *KeyConfig) (err error) {
+	return conn.RemoveKeyWithContext(context.Background(), keyCfg)
+}
+
+// RemoveKeyWithContext removes the key identified by AccessKey, or the swift
+// key of Subuser when KeyType is KeyTypeSwift.
+func (conn *Connection) RemoveKeyWithContext(ctx context.Context, keyCfg *KeyConfig) (err error) {
+	return conn.adminRequest(ctx, "DELETE", "/admin/user", keyCfg.args(), nil, nil)
+}
@@ -0,0 +1,85 @@
+package radosgwapi
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/url"
+)
+
+// Quota types of GetUserQuota and SetUserQuota.
+const (
+	QuotaTypeUser   = "user"
+	QuotaTypeBucket = "bucket"
+)
+
+func (conn *Connection) GetUserQuota(uid, quotaType string) (quota *Quota, err error) {
+	return conn.GetUserQuotaWithContext(context.Background(), uid, quotaType)
+}
+
+// GetUserQuotaWithContext returns the user's own quota for QuotaTypeUser, or
+// the default quota of each of the user's buckets for QuotaTypeBucket.
+func (conn *Connection) GetUserQuotaWithContext(ctx context.Context, uid, quotaType string) (quota *Quota, err error) {
+	args := url.Values{}
+	args.Add("quota", "")
+	args.Add("uid", uid)
+	args.Add("quota-type", quotaType)
+
+	quota = &Quota{}
+	err = conn.adminRequest(ctx, "GET", "/admin/user", args, nil, quota)
+	return
+}
+
+func (conn *Connection) SetUserQuota(uid, quotaType string, quota *Quota) (err error) {
+	return conn.SetUserQuotaWithContext(context.Background(), uid, quotaType, quota)
+}
+
+// SetUserQuotaWithContext replaces the user or user-level bucket quota.
+// MaxSize and MaxObjects of -1 mean unlimited.
+func (conn *Connection) SetUserQuotaWithContext(ctx context.Context, uid, quotaType string, quota *Quota) (err error) {
+	body, err := json.Marshal(quota)
+	if nil != err {
+		return
+	}
+
+	args := url.Values{}
+	args.Add("quota", "")
+	args.Add("uid", uid)
+	args.Add("quota-type", quotaType)
+
+	return conn.adminRequest(ctx, "PUT", "/admin/user", args, bytes.NewReader(body), nil)
+}
+
+func (conn *Connection) GetBucketQuota(bucketName string) (quota *Quota, err error) {
+	return conn.GetBucketQuotaWithContext(context.Background(), bucketName)
+}
+
+// GetBucketQuotaWithContext returns the quota set on a single bucket, as
+// reported by GetBucketStats.
+func (conn *Connection) GetBucketQuotaWithContext(ctx context.Context, bucketName string) (quota *Quota, err error) {
+	bucketStats, err := conn.GetBucketStatsWithContext(ctx, bucketName)
+	if nil != err {
+		return
+	}
+
+	return &bucketStats.BucketQuota, nil
+}
+
+func (conn *Connection) SetBucketQuota(uid, bucketName string, quota *Quota) (err error) {
+	return conn.SetBucketQuotaWithContext(context.Background(), uid, bucketName, quota)
+}
+
+// SetBucketQuotaWithContext replaces the quota of a single bucket owned by uid.
+func (conn *Connection) SetBucketQuotaWithContext(ctx context.Context, uid, bucketName string, quota *Quota) (err error) {
+	body, err := json.Marshal(quota)
+	if nil != err {
+		return
+	}
+
+	args := url.Values{}
+	args.Add("quota", "")
+	args.Add("uid", uid)
+	args.Add("bucket", bucketName)
+
+	return conn.adminRequest(ctx, "PUT", "/admin/bucket", args, bytes.NewReader(body), nil)
+}
@@ -0,0 +1,351 @@
+package radosgwapi_test
+
+import (
+	"encoding/json"
+	"errors"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"testing"
+	"time"
+
+	radosgwapi "github.com/changjixiong/radosgw-api"
+)
+
+const userInfoJSON = `{"tenant":"","user_id":"alice","display_name":"Alice","email":"alice@example.com",
+"suspended":0,"max_buckets":10,"subusers":[{"id":"alice:swift","permissions":"full-control"}],
+"keys":[{"user":"alice","access_key":"AK","secret_key":"SK"}],
+"swift_keys":[{"user":"alice:swift","secret_key":"SWK"}],
+"caps":[{"type":"usage","perm":"*"},{"type":"users","perm":"read"}],
+"op_mask":"read, write, delete","default_placement":"","placement_tags":[],
+"bucket_quota":{"enabled":false,"check_on_raw":false,"max_size":-1,"max_size_kb":0,"max_objects":-1},
+"user_quota":{"enabled":true,"check_on_raw":false,"max_size":1024,"max_size_kb":1,"max_objects":100},
+"temp_url_keys":[],"type":"rgw","stats":{"size":10,"size_actual":4096,"num_objects":1}}`
+
+func TestAdminUser(t *testing.T) {
+	var lastMethod string
+	var lastQuery url.Values
+	conn, server := newTestConnection(func(w http.ResponseWriter, r *http.Request) {
+		lastMethod, lastQuery = r.Method, r.URL.Query()
+		if "/admin/user" != r.URL.Path {
+			t.Errorf("unexpected path %s", r.URL.Path)
+		}
+		if "missing" == lastQuery.Get("uid") {
+			w.WriteHeader(http.StatusNotFound)
+			w.Write([]byte(`{"Code":"NoSuchUser","RequestId":"tx1","HostId":"h"}`))
+			return
+		}
+		if "DELETE" != r.Method {
+			w.Write([]byte(userInfoJSON))
+		}
+	})
+	defer server.Close()
+
+	maxBuckets := 10
+	suspended := false
+	userInfo, err := conn.CreateUser(&radosgwapi.UserConfig{
+		UID:         "alice",
+		DisplayName: "Alice",
+		Email:       "alice@example.com",
+		MaxBuckets:  &maxBuckets,
+		Suspended:   &suspended,
+	})
+	if nil != err {
+		t.Fatal(err)
+	}
+	if "PUT" != lastMethod || "Alice" != lastQuery.Get("display-name") || "10" != lastQuery.Get("max-buckets") ||
+		"false" != lastQuery.Get("suspended") || lastQuery["generate-key"] != nil {
+		t.Errorf("unexpected create request %s %v", lastMethod, lastQuery)
+	}
+	if "alice" != userInfo.UserId || 1 != len(userInfo.Keys) || "AK" != userInfo.Keys[0].AccessKey ||
+		"SWK" != userInfo.SwiftKeys[0].SecretKey || 2 != len(userInfo.Caps) || !userInfo.UserQuota.Enabled ||
+		100 != userInfo.UserQuota.MaxObjects || 1 != userInfo.Stats.NumObjects {
+		t.Errorf("unexpected user info %+v", userInfo)
+	}
+
+	if _, err = conn.ModifyUser(&radosgwapi.UserConfig{UID: "alice", Email: "new@example.com"}); nil != err {
+		t.Fatal(err)
+	}
+	if "POST" != lastMethod || "new@example.com" != lastQuery.Get("email") || lastQuery["display-name"] != nil {
+		t.Errorf("unexpected modify request %s %v", lastMethod, lastQuery)
+	}
+
+	if _, err = conn.GetUserInfo("alice", true); nil != err || "true" != lastQuery.Get("stats") {
+		t.Errorf("unexpected get request %v %v", err, lastQuery)
+	}
+
+	if err = conn.RemoveUser("alice", true); nil != err {
+		t.Fatal(err)
+	}
+	if "DELETE" != lastMethod || "true" != lastQuery.Get("purge-data") {
+		t.Errorf("unexpected remove request %s %v", lastMethod, lastQuery)
+	}
+
+	if _, err = conn.GetUserInfo("missing", false); !errors.Is(err, radosgwapi.ErrNoSuchUser) {
+		t.Errorf("expected ErrNoSuchUser, got %v", err)
+	}
+}
+
+func TestAdminSubuserAndKey(t *testing.T) {
+	var lastMethod string
+	var lastQuery url.Values
+	conn, server := newTestConnection(func(w http.ResponseWriter, r *http.Request) {
+		lastMethod, lastQuery = r.Method, r.URL.Query()
+		switch {
+		case "DELETE" == r.Method:
+		case lastQuery["key"] != nil:
+			w.Write([]byte(`[{"user":"alice","access_key":"AK2","secret_key":"SK2"}]`))
+		default:
+			w.Write([]byte(`[{"id":"alice:swift","permissions":"read"}]`))
+		}
+	})
+	defer server.Close()
+
+	subusers, err := conn.CreateSubuser(&radosgwapi.SubuserConfig{
+		UID:     "alice",
+		Subuser: "swift",
+		KeyType: radosgwapi.KeyTypeSwift,
+		Access:  radosgwapi.AccessRead,
+	})
+	if nil != err || 1 != len(subusers) || "read" != subusers[0].Permissions {
+		t.Fatalf("unexpected result %v %v", subusers, err)
+	}
+	if "PUT" != lastMethod || "swift" != lastQuery.Get("subuser") || "read" != lastQuery.Get("access") {
+		t.Errorf("unexpected create subuser request %s %v", lastMethod, lastQuery)
+	}
+
+	generate := true
+	keys, err := conn.CreateKey(&radosgwapi.KeyConfig{UID: "alice", KeyType: radosgwapi.KeyTypeS3, GenerateKey: &generate})
+	if nil != err || 1 != len(keys) || "AK2" != keys[0].AccessKey {
+		t.Fatalf("unexpected result %v %v", keys, err)
+	}
+	if "PUT" != lastMethod || "true" != lastQuery.Get("generate-key") || "s3" != lastQuery.Get("key-type") {
+		t.Errorf("unexpected create key request %s %v", lastMethod, lastQuery)
+	}
+
+	if err = conn.RemoveKey(&radosgwapi.KeyConfig{UID: "alice", AccessKey: "AK"}); nil != err {
+		t.Fatal(err)
+	}
+	if "DELETE" != lastMethod || lastQuery["key"] == nil || "AK" != lastQuery.Get("access-key") {
+		t.Errorf("unexpected remove key request %s %v", lastMethod, lastQuery)
+	}
+
+	if err = conn.RemoveSubuser("alice", "swift", true); nil != err {
+		t.Fatal(err)
+	}
+	if "DELETE" != lastMethod || "true" != lastQuery.Get("purge-keys") {
+		t.Errorf("unexpected remove subuser request %s %v", lastMethod, lastQuery)
+	}
+}
+
+func TestAdminQuota(t *testing.T) {
+	var lastMethod, lastPath, lastBody string
+	var lastQuery url.Values
+	conn, server := newTestConnection(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := ioutil.ReadAll(r.Body)
+		lastMethod, lastPath, lastQuery, lastBody = r.Method, r.URL.Path, r.URL.Query(), string(body)
+		switch {
+		case "PUT" == r.Method:
+		case "/admin/bucket" == r.URL.Path:
+			w.Write([]byte(`{"bucket":"b","bucket_quota":{"enabled":true,"max_size":2048,"max_objects":-1}}`))
+		default:
+			w.Write([]byte(`{"enabled":true,"check_on_raw":false,"max_size":1024,"max_size_kb":1,"max_objects":100}`))
+		}
+	})
+	defer server.Close()
+
+	quota, err := conn.GetUserQuota("alice", radosgwapi.QuotaTypeUser)
+	if nil != err || !quota.Enabled || 1024 != quota.MaxSize || 100 != quota.MaxObjects {
+		t.Fatalf("unexpected quota %+v %v", quota, err)
+	}
+	if lastQuery["quota"] == nil || "user" != lastQuery.Get("quota-type") {
+		t.Errorf("unexpected get quota request %v", lastQuery)
+	}
+
+	quota.MaxObjects = 200
+	if err = conn.SetUserQuota("alice", radosgwapi.QuotaTypeBucket, quota); nil != err {
+		t.Fatal(err)
+	}
+	roundTrip := &radosgwapi.Quota{}
+	json.Unmarshal([]byte(lastBody), roundTrip)
+	if "PUT" != lastMethod || "bucket" != lastQuery.Get("quota-type") || *roundTrip != *quota {
+		t.Errorf("unexpected set quota request %s %v %s", lastMethod, lastQuery, lastBody)
+	}
+
+	quota, err = conn.GetBucketQuota("b")
+	if nil != err || 2048 != quota.MaxSize || -1 != quota.MaxObjects {
+		t.Fatalf("unexpected bucket quota %+v %v", quota, err)
+	}
+
+	if err = conn.SetBucketQuota("alice", "b", quota); nil != err {
+		t.Fatal(err)
+	}
+	if "/admin/bucket" != lastPath || "b" != lastQuery.Get("bucket") || lastQuery["quota"] == nil {
+		t.Errorf("unexpected set bucket quota request %s %v", lastPath, lastQuery)
+	}
+}
+
+const usageJSON = `{"entries":[
+{"user":"alice","buckets":[
+ {"bucket":"b1","time":"2020-01-01 10:00:00.000000Z","epoch":1577872800,"owner":"alice","categories":[
+  {"category":"get_obj","bytes_sent":100,"bytes_received":0,"ops":2,"successful_ops":2},
+  {"category":"put_obj","bytes_sent":0,"bytes_received":50,"ops":1,"successful_ops":1}]},
+ {"bucket":"b1","time":"2020-01-02 10:00:00.000000Z","epoch":1577959200,"owner":"alice","categories":[
+  {"category":"get_obj","bytes_sent":10,"bytes_received":0,"ops":1,"successful_ops":0}]}]},
+{"user":"bob","buckets":[
+ {"bucket":"b2","time":"2020-01-02 11:00:00.000000Z","epoch":1577962800,"owner":"bob","categories":[
+  {"category":"get_obj","bytes_sent":5,"bytes_received":0,"ops":1,"successful_ops":1}]}]}],
+"summary":[{"user":"alice","categories":[],"total":{"bytes_sent":110,"bytes_received":50,"ops":4,"successful_ops":3}}]}`
+
+func TestAdminUsage(t *testing.T) {
+	var lastMethod string
+	var lastQuery url.Values
+	conn, server := newTestConnection(func(w http.ResponseWriter, r *http.Request) {
+		lastMethod, lastQuery = r.Method, r.URL.Query()
+		if "GET" == r.Method {
+			w.Write([]byte(usageJSON))
+		}
+	})
+	defer server.Close()
+
+	showEntries := true
+	usage, err := conn.GetUsage(&radosgwapi.UsageConfig{
+		Start:       time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC),
+		End:         time.Date(2020, 2, 1, 0, 0, 0, 0, time.UTC),
+		ShowEntries: &showEntries,
+	})
+	if nil != err {
+		t.Fatal(err)
+	}
+	if "2020-01-01 00:00:00" != lastQuery.Get("start") || "true" != lastQuery.Get("show-entries") ||
+		lastQuery["show-summary"] != nil || lastQuery["uid"] != nil {
+		t.Errorf("unexpected usage request %v", lastQuery)
+	}
+	if 110 != usage.Summary[0].Total.BytesSent {
+		t.Errorf("unexpected summary %+v", usage.Summary)
+	}
+
+	byUser := usage.ByUser()
+	if 110 != byUser["alice"].BytesSent || 50 != byUser["alice"].BytesReceived || 4 != byUser["alice"].Ops || 5 != byUser["bob"].BytesSent {
+		t.Errorf("unexpected ByUser %+v", byUser)
+	}
+	if byBucket := usage.ByBucket(); 4 != byBucket["b1"].Ops || 1 != byBucket["b2"].Ops {
+		t.Errorf("unexpected ByBucket %+v", byBucket)
+	}
+	if byCategory := usage.ByCategory(); 115 != byCategory["get_obj"].BytesSent || 3 != byCategory["get_obj"].SuccessfulOps {
+		t.Errorf("unexpected ByCategory %+v", byCategory)
+	}
+	if byDay := usage.ByDay(); 3 != byDay["2020-01-01"].Ops || 15 != byDay["2020-01-02"].BytesSent {
+		t.Errorf("unexpected ByDay %+v", byDay)
+	}
+
+	if err = conn.TrimUsage(&radosgwapi.UsageConfig{UID: "alice"}); nil != err {
+		t.Fatal(err)
+	}
+	if "DELETE" != lastMethod || "alice" != lastQuery.Get("uid") || lastQuery["remove-all"] != nil {
+		t.Errorf("unexpected trim request %s %v", lastMethod, lastQuery)
+	}
+}
+
+func TestAdminBucket(t *testing.T) {
+	var lastMethod string
+	var lastQuery url.Values
+	conn, server := newTestConnection(func(w http.ResponseWriter, r *http.Request) {
+		lastMethod, lastQuery = r.Method, r.URL.Query()
+		switch {
+		case "GET" != r.Method:
+		case lastQuery["index"] != nil:
+			w.Write([]byte(`["_multipart_k.2~abc.meta"]
+{"existing_header":{"usage":{"rgw.main":{"size":10,"num_objects":2}}},
+"calculated_header":{"usage":{"rgw.main":{"size":10,"num_objects":1}}}}`))
+		case lastQuery["bucket"] != nil:
+			w.Write([]byte(`{"bucket":"b","id":"zone.1","owner":"alice","usage":{"rgw.main":{"size":10,"num_objects":1}},
+"bucket_quota":{"enabled":true,"max_size":-1,"max_objects":5}}`))
+		case lastQuery["stats"] != nil:
+			w.Write([]byte(`[{"bucket":"b","owner":"alice"},{"bucket":"c","owner":"alice"}]`))
+		default:
+			w.Write([]byte(`["b","c"]`))
+		}
+	})
+	defer server.Close()
+
+	bucketStats, err := conn.GetBucketStats("b")
+	if nil != err || "zone.1" != bucketStats.Id || 1 != bucketStats.Usage["rgw.main"].NumObjects || 5 != bucketStats.BucketQuota.MaxObjects {
+		t.Fatalf("unexpected bucket stats %+v %v", bucketStats, err)
+	}
+
+	buckets, err := conn.ListUserBuckets("alice")
+	if nil != err || 2 != len(buckets) || "c" != buckets[1] {
+		t.Errorf("unexpected buckets %v %v", buckets, err)
+	}
+	allStats, err := conn.ListUserBucketStats("alice")
+	if nil != err || 2 != len(allStats) || "c" != allStats[1].Bucket {
+		t.Errorf("unexpected bucket stats %v %v", allStats, err)
+	}
+
+	if err = conn.UnlinkBucket("b", "alice"); nil != err || "POST" != lastMethod || "alice" != lastQuery.Get("uid") {
+		t.Errorf("unexpected unlink request %s %v %v", lastMethod, lastQuery, err)
+	}
+	if err = conn.LinkBucket("b", bucketStats.Id, "bob"); nil != err || "PUT" != lastMethod ||
+		"bob" != lastQuery.Get("uid") || "zone.1" != lastQuery.Get("bucket-id") {
+		t.Errorf("unexpected link request %s %v %v", lastMethod, lastQuery, err)
+	}
+
+	indexCheck, err := conn.CheckBucketIndex("b", true, false)
+	if nil != err || 1 != len(indexCheck.InvalidMultipartEntries) ||
+		2 != indexCheck.ExistingHeader.Usage["rgw.main"].NumObjects || 1 != indexCheck.CalculatedHeader.Usage["rgw.main"].NumObjects {
+		t.Errorf("unexpected index check %+v %v", indexCheck, err)
+	}
+	if "true" != lastQuery.Get("check-objects") || "false" != lastQuery.Get("fix") {
+		t.Errorf("unexpected index check request %v", lastQuery)
+	}
+
+	if err = conn.RemoveBucket("b", true); nil != err || "DELETE" != lastMethod || "true" != lastQuery.Get("purge-objects") {
+		t.Errorf("unexpected remove request %s %v %v", lastMethod, lastQuery, err)
+	}
+}
+
+func TestAdminCaps(t *testing.T) {
+	caps, err := radosgwapi.ParseCaps("users=read;buckets=*; usage=read,write")
+	if nil != err || "buckets=*;usage=*;users=read" != radosgwapi.FormatCaps(caps) {
+		t.Errorf("unexpected caps %v %v", caps, err)
+	}
+	if _, err = radosgwapi.ParseCaps("users=admin"); nil == err {
+		t.Error("expected error for invalid permission")
+	}
+	if _, err = radosgwapi.ParseCaps("nosuch=read"); nil == err {
+		t.Error("expected error for invalid type")
+	}
+
+	current, _ := radosgwapi.ParseCaps("users=*;buckets=read;zone=read")
+	desired, _ := radosgwapi.ParseCaps("users=read;buckets=*;usage=read")
+	add, remove := radosgwapi.DiffCaps(current, desired)
+	if "buckets=write;usage=read" != radosgwapi.FormatCaps(add) || "users=write;zone=read" != radosgwapi.FormatCaps(remove) {
+		t.Errorf("unexpected diff +%v -%v", add, remove)
+	}
+
+	userInfo := &radosgwapi.UserInfo{Caps: current}
+	if !userInfo.HasCap(radosgwapi.CapUsers, radosgwapi.PermWrite) || userInfo.HasCap(radosgwapi.CapBuckets, radosgwapi.PermAll) {
+		t.Error("unexpected HasCap result")
+	}
+
+	var lastMethod string
+	var lastQuery url.Values
+	conn, server := newTestConnection(func(w http.ResponseWriter, r *http.Request) {
+		lastMethod, lastQuery = r.Method, r.URL.Query()
+		w.Write([]byte(`[{"type":"buckets","perm":"*"},{"type":"usage","perm":"read"}]`))
+	})
+	defer server.Close()
+
+	userCaps, err := conn.AddUserCaps("alice", add)
+	if nil != err || 2 != len(userCaps) || "PUT" != lastMethod || lastQuery["caps"] == nil ||
+		"buckets=write;usage=read" != lastQuery.Get("user-caps") {
+		t.Errorf("unexpected add caps %v %v %s %v", userCaps, err, lastMethod, lastQuery)
+	}
+	if _, err = conn.RemoveUserCaps("alice", remove); nil != err || "DELETE" != lastMethod {
+		t.Errorf("unexpected remove caps %v %s", err, lastMethod)
+	}
+	if _, err = conn.AddUserCaps("alice", []radosgwapi.UserCap{{Type: "users", Perm: "all"}}); nil == err {
+		t.Error("expected validation error")
+	}
+}
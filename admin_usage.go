@@ -0,0 +1,122 @@
+package radosgwapi
+
+import (
+	"context"
+	"net/url"
+	"strconv"
+	"time"
+)
+
+const usageTimeFormat = "2006-01-02 15:04:05"
+
+// UsageConfig holds the parameters of GetUsage and TrimUsage. An empty UID
+// selects all users and zero times leave the range open.
+type UsageConfig struct {
+	UID         string
+	Start       time.Time
+	End         time.Time
+	ShowEntries *bool
+	ShowSummary *bool
+	RemoveAll   bool
+}
+
+func (usageCfg *UsageConfig) args() url.Values {
+	args := url.Values{}
+
+	if "" != usageCfg.UID {
+		args.Add("uid", usageCfg.UID)
+	}
+	if !usageCfg.Start.IsZero() {
+		args.Add("start", usageCfg.Start.UTC().Format(usageTimeFormat))
+	}
+	if !usageCfg.End.IsZero() {
+		args.Add("end", usageCfg.End.UTC().Format(usageTimeFormat))
+	}
+
+	return args
+}
+
+func (conn *Connection) GetUsage(usageCfg *UsageConfig) (usage *Usage, err error) {
+	return conn.GetUsageWithContext(context.Background(), usageCfg)
+}
+
+func (conn *Connection) GetUsageWithContext(ctx context.Context, usageCfg *UsageConfig) (usage *Usage, err error) {
+	args := usageCfg.args()
+	if nil != usageCfg.ShowEntries {
+		args.Add("show-entries", strconv.FormatBool(*usageCfg.ShowEntries))
+	}
+	if nil != usageCfg.ShowSummary {
+		args.Add("show-summary", strconv.FormatBool(*usageCfg.ShowSummary))
+	}
+
+	usage = &Usage{}
+	err = conn.adminRequest(ctx, "GET", "/admin/usage", args, nil, usage)
+	return
+}
+
+func (conn *Connection) TrimUsage(usageCfg *UsageConfig) (err error) {
+	return conn.TrimUsageWithContext(context.Background(), usageCfg)
+}
+
+// TrimUsageWithContext removes usage logs in the range. Without a UID,
+// RemoveAll must be set to trim the logs of all users.
+func (conn *Connection) TrimUsageWithContext(ctx context.Context, usageCfg *UsageConfig) (err error) {
+	args := usageCfg.args()
+	if usageCfg.RemoveAll {
+		args.Add("remove-all", "true")
+	}
+
+	return conn.adminRequest(ctx, "DELETE", "/admin/usage", args, nil, nil)
+}
+
+func (counters *UsageCounters) Add(other UsageCounters) {
+	counters.BytesSent += other.BytesSent
+	counters.BytesReceived += other.BytesReceived
+	counters.Ops += other.Ops
+	counters.SuccessfulOps += other.SuccessfulOps
+}
+
+// aggregate sums the counters of every entry under the key returned by keyOf.
+func (usage *Usage) aggregate(keyOf func(user string, bucket *UsageBucket, category string) string) map[string]UsageCounters {
+	result := map[string]UsageCounters{}
+	for _, entry := range usage.Entries {
+		for i := range entry.Buckets {
+			bucket := &entry.Buckets[i]
+			for _, category := range bucket.Categories {
+				key := keyOf(entry.User, bucket, category.Category)
+				counters := result[key]
+				counters.Add(category.UsageCounters)
+				result[key] = counters
+			}
+		}
+	}
+	return result
+}
+
+// ByUser sums the entries per user.
+func (usage *Usage) ByUser() map[string]UsageCounters {
+	return usage.aggregate(func(user string, bucket *UsageBucket, category string) string {
+		return user
+	})
+}
+
+// ByBucket sums the entries per bucket name.
+func (usage *Usage) ByBucket() map[string]UsageCounters {
+	return usage.aggregate(func(user string, bucket *UsageBucket, category string) string {
+		return bucket.Bucket
+	})
+}
+
+// ByCategory sums the entries per operation category, such as get_obj.
+func (usage *Usage) ByCategory() map[string]UsageCounters {
+	return usage.aggregate(func(user string, bucket *UsageBucket, category string) string {
+		return category
+	})
+}
+
+// ByDay sums the entries per UTC day, keyed as 2006-01-02.
+func (usage *Usage) ByDay() map[string]UsageCounters {
+	return usage.aggregate(func(user string, bucket *UsageBucket, category string) string {
+		return time.Unix(bucket.Epoch, 0).UTC().Format("2006-01-02")
+	})
+}
@@ -0,0 +1,72 @@
+package radosgwapi
+
+import (
+	"bytes"
+	"context"
+	"crypto/md5"
+	"encoding/base64"
+	"encoding/xml"
+	"net/http"
+	"net/url"
+)
+
+// Lifecycle rule states.
+const (
+	LifecycleEnabled  = "Enabled"
+	LifecycleDisabled = "Disabled"
+)
+
+func (conn *Connection) GetBucketLifecycle(bucket string) (result *LifecycleConfiguration, err error) {
+	return conn.GetBucketLifecycleWithContext(context.Background(), bucket)
+}
+
+// GetBucketLifecycleWithContext returns the lifecycle rules of the bucket, or
+// an error matching ErrNoSuchLifecycle when it has none.
+func (conn *Connection) GetBucketLifecycleWithContext(ctx context.Context, bucket string) (result *LifecycleConfiguration, err error) {
+	args := url.Values{}
+	args.Add("lifecycle", "")
+
+	_, _, body, err := conn.RequestWithContext(ctx, "GET", "/"+bucket, args, nil)
+	if nil != err {
+		return
+	}
+
+	result = &LifecycleConfiguration{}
+	err = xml.Unmarshal(body, result)
+	return
+}
+
+func (conn *Connection) PutBucketLifecycle(bucket string, lifecycle *LifecycleConfiguration) (err error) {
+	return conn.PutBucketLifecycleWithContext(context.Background(), bucket, lifecycle)
+}
+
+// PutBucketLifecycleWithContext replaces all the lifecycle rules of the
+// bucket with lifecycle.
+func (conn *Connection) PutBucketLifecycleWithContext(ctx context.Context, bucket string, lifecycle *LifecycleConfiguration) (err error) {
+	putBody, err := xml.Marshal(lifecycle)
+	if nil != err {
+		return
+	}
+
+	args := url.Values{}
+	args.Add("lifecycle", "")
+
+	sum := md5.Sum(putBody)
+	reqHeader := http.Header{}
+	reqHeader.Set("Content-Md5", base64.StdEncoding.EncodeToString(sum[:]))
+
+	_, _, _, err = conn.requestWithHeader(ctx, "PUT", "/"+bucket, args, reqHeader, bytes.NewReader(putBody))
+	return
+}
+
+func (conn *Connection) DeleteBucketLifecycle(bucket string) (err error) {
+	return conn.DeleteBucketLifecycleWithContext(context.Background(), bucket)
+}
+
+func (conn *Connection) DeleteBucketLifecycleWithContext(ctx context.Context, bucket string) (err error) {
+	args := url.Values{}
+	args.Add("lifecycle", "")
+
+	_, _, _, err = conn.RequestWithContext(ctx, "DELETE", "/"+bucket, args, nil)
+	return
+}
@@ -0,0 +1,232 @@
+package radosgwapi_test
+
+import (
+	"context"
+	"errors"
+	"io/ioutil"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	radosgwapi "github.com/changjixiong/radosgw-api"
+)
+
+func TestListBuckets(t *testing.T) {
+	conn, server := newTestConnection(func(w http.ResponseWriter, r *http.Request) {
+		if "/" != r.URL.Path {
+			t.Errorf("unexpected path %s", r.URL.Path)
+		}
+		if r.URL.Query()["stats"] != nil {
+			w.Header().Set("X-RGW-Object-Count", "12")
+			w.Header().Set("X-RGW-Bytes-Used", "4096")
+		}
+		w.Write([]byte(`<?xml version="1.0" encoding="UTF-8"?><ListAllMyBucketsResult xmlns="http://s3.amazonaws.com/doc/2006-03-01/">` +
+			`<Owner><ID>alice</ID><DisplayName>Alice</DisplayName></Owner><Buckets>` +
+			`<Bucket><Name>b1</Name><CreationDate>2020-01-01T00:00:00.000Z</CreationDate></Bucket>` +
+			`<Bucket><Name>b2</Name><CreationDate>2020-02-01T00:00:00.000Z</CreationDate></Bucket>` +
+			`</Buckets></ListAllMyBucketsResult>`))
+	})
+	defer server.Close()
+
+	result, err := conn.ListBuckets(nil)
+	if nil != err {
+		t.Fatal(err)
+	}
+	if "alice" != result.Owner.ID || 2 != len(result.Buckets) || "b2" != result.Buckets[1].Name ||
+		2 != int(result.Buckets[1].CreationDate.Month()) || nil != result.Stats {
+		t.Errorf("unexpected result %+v", result)
+	}
+
+	result, err = conn.ListBuckets(&radosgwapi.ListBucketsConfig{Stats: true})
+	if nil != err {
+		t.Fatal(err)
+	}
+	if "b1" != result.Buckets[0].Name || 12 != result.Stats.ObjectCount || 4096 != result.Stats.BytesUsed {
+		t.Errorf("unexpected result %+v %+v", result, result.Stats)
+	}
+}
+
+func TestTenantBucket(t *testing.T) {
+	name := radosgwapi.TenantBucket("t1", "b1")
+	if tenant, bucketName := radosgwapi.SplitTenantBucket(name); "t1:b1" != name || "t1" != tenant || "b1" != bucketName {
+		t.Errorf("unexpected split of %s: %s %s", name, tenant, bucketName)
+	}
+	if name := radosgwapi.TenantBucket("", "b1"); "b1" != name {
+		t.Errorf("unexpected name %s", name)
+	}
+}
+
+func TestBucketVersioning(t *testing.T) {
+	var putBody, mfa string
+	conn, server := newTestConnection(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Query()["versioning"] == nil {
+			t.Errorf("unexpected query %s", r.URL.RawQuery)
+		}
+		if "PUT" == r.Method {
+			body, _ := ioutil.ReadAll(r.Body)
+			putBody, mfa = string(body), r.Header.Get("X-Amz-Mfa")
+			return
+		}
+		w.Write([]byte(`<VersioningConfiguration xmlns="http://s3.amazonaws.com/doc/2006-03-01/"><Status>Suspended</Status></VersioningConfiguration>`))
+	})
+	defer server.Close()
+
+	err := conn.PutBucketVersioning(&radosgwapi.BucketVersioningConfig{Bucket: "b", Status: radosgwapi.VersioningEnabled,
+		MFADelete: radosgwapi.MFADeleteEnabled, MFA: "serial 123456"})
+	if nil != err || `<VersioningConfiguration><Status>Enabled</Status><MfaDelete>Enabled</MfaDelete></VersioningConfiguration>` != putBody ||
+		"serial 123456" != mfa {
+		t.Errorf("unexpected request %s %q %v", putBody, mfa, err)
+	}
+
+	result, err := conn.GetBucketVersioning("b")
+	if nil != err || radosgwapi.VersioningSuspended != result.Status || "" != result.MfaDelete {
+		t.Errorf("unexpected result %+v %v", result, err)
+	}
+}
+
+func TestVersionIterator(t *testing.T) {
+	var queries []string
+	conn, server := newTestConnection(func(w http.ResponseWriter, r *http.Request) {
+		queries = append(queries, r.URL.RawQuery)
+		if "" == r.URL.Query().Get("key-marker") {
+			w.Write([]byte(`<ListVersionsResult><IsTruncated>true</IsTruncated><NextKeyMarker>a</NextKeyMarker><NextVersionIdMarker>v2</NextVersionIdMarker>` +
+				`<DeleteMarker><Key>a</Key><VersionId>v3</VersionId><IsLatest>true</IsLatest><LastModified>2020-01-03T00:00:00.000Z</LastModified></DeleteMarker>` +
+				`<Version><Key>a</Key><VersionId>v2</VersionId><IsLatest>false</IsLatest><LastModified>2020-01-02T00:00:00.000Z</LastModified><Size>10</Size></Version>` +
+				`</ListVersionsResult>`))
+			return
+		}
+		w.Write([]byte(`<ListVersionsResult><IsTruncated>false</IsTruncated><EncodingType>url</EncodingType>` +
+			`<Version><Key>a</Key><VersionId>v1</VersionId><IsLatest>false</IsLatest><LastModified>2020-01-01T00:00:00.000Z</LastModified><Size>5</Size></Version>` +
+			`<CommonPrefixes><Prefix>dir/</Prefix></CommonPrefixes></ListVersionsResult>`))
+	})
+	defer server.Close()
+
+	it := conn.NewVersionIterator(context.Background(), &radosgwapi.ListObjectVersionsConfig{Bucket: "b", Delimiter: "/"})
+	versions := []radosgwapi.ObjectVersion{}
+	for it.Next() {
+		versions = append(versions, it.Version())
+	}
+	if nil != it.Err() {
+		t.Fatal(it.Err())
+	}
+
+	if 3 != len(versions) || !versions[0].IsDeleteMarker() || !versions[0].IsLatest || versions[1].IsDeleteMarker() ||
+		"v1" != versions[2].VersionId || 5 != versions[2].Size || 2 != versions[1].LastModified.Day() {
+		t.Errorf("unexpected versions %+v", versions)
+	}
+	if 1 != len(it.CommonPrefixes()) || 2 != len(queries) || !strings.Contains(queries[1], "version-id-marker=v2") {
+		t.Errorf("unexpected prefixes %v, queries %v", it.CommonPrefixes(), queries)
+	}
+}
+
+func TestBucketLifecycle(t *testing.T) {
+	var stored []byte
+	var contentMD5 string
+	conn, server := newTestConnection(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Query()["lifecycle"] == nil {
+			t.Errorf("unexpected query %s", r.URL.RawQuery)
+		}
+		switch r.Method {
+		case "PUT":
+			stored, _ = ioutil.ReadAll(r.Body)
+			contentMD5 = r.Header.Get("Content-Md5")
+		case "GET":
+			if nil == stored {
+				w.WriteHeader(http.StatusNotFound)
+				w.Write([]byte(`<Error><Code>NoSuchLifecycleConfiguration</Code></Error>`))
+				return
+			}
+			w.Write(stored)
+		case "DELETE":
+			stored = nil
+			w.WriteHeader(http.StatusNoContent)
+		}
+	})
+	defer server.Close()
+
+	if _, err := conn.GetBucketLifecycle("b"); !errors.Is(err, radosgwapi.ErrNoSuchLifecycle) {
+		t.Errorf("expected ErrNoSuchLifecycle, got %v", err)
+	}
+
+	date := time.Date(2030, 1, 1, 0, 0, 0, 0, time.UTC)
+	lifecycle := &radosgwapi.LifecycleConfiguration{Rules: []radosgwapi.LifecycleRule{{
+		ID:                          "pictures",
+		Status:                      radosgwapi.LifecycleEnabled,
+		Filter:                      &radosgwapi.LifecycleFilter{And: &radosgwapi.LifecycleAnd{Prefix: "tmp/", Tags: []radosgwapi.Tag{{Key: "k", Value: "v"}}}},
+		Expiration:                  &radosgwapi.LifecycleExpiration{Date: &date},
+		NoncurrentVersionExpiration: &radosgwapi.NoncurrentVersionExpiration{NoncurrentDays: 30},
+		Transitions:                 []radosgwapi.LifecycleTransition{{Days: 7, StorageClass: "COLD"}},
+	}, {
+		Status:                         radosgwapi.LifecycleEnabled,
+		Filter:                         &radosgwapi.LifecycleFilter{},
+		Expiration:                     &radosgwapi.LifecycleExpiration{ExpiredObjectDeleteMarker: true},
+		AbortIncompleteMultipartUpload: &radosgwapi.AbortIncompleteMultipartUpload{DaysAfterInitiation: 2},
+	}}}
+	if err := conn.PutBucketLifecycle("b", lifecycle); nil != err || "" == contentMD5 {
+		t.Fatalf("unexpected put %v, Content-MD5 %q", err, contentMD5)
+	}
+	for _, expected := range []string{
+		`<Filter><And><Prefix>tmp/</Prefix><Tag><Key>k</Key><Value>v</Value></Tag></And></Filter>`,
+		`<Expiration><Date>2030-01-01T00:00:00Z</Date></Expiration>`,
+		`<Transition><Days>7</Days><StorageClass>COLD</StorageClass></Transition>`,
+		`<Filter></Filter><Expiration><ExpiredObjectDeleteMarker>true</ExpiredObjectDeleteMarker></Expiration>`,
+	} {
+		if !strings.Contains(string(stored), expected) {
+			t.Errorf("expected %s in %s", expected, stored)
+		}
+	}
+
+	result, err := conn.GetBucketLifecycle("b")
+	if nil != err || 2 != len(result.Rules) || "v" != result.Rules[0].Filter.And.Tags[0].Value ||
+		!date.Equal(*result.Rules[0].Expiration.Date) || 2 != result.Rules[1].AbortIncompleteMultipartUpload.DaysAfterInitiation {
+		t.Errorf("unexpected result %+v %v", result, err)
+	}
+
+	if err := conn.DeleteBucketLifecycle("b"); nil != err || nil != stored {
+		t.Errorf("unexpected delete %v", err)
+	}
+}
+
+func TestCreateBucketLifecycle(t *testing.T) {
+	var requests []string
+	conn, server := newTestConnection(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := ioutil.ReadAll(r.Body)
+		requests = append(requests, r.Method+" "+r.URL.Path+"?"+r.URL.RawQuery+" "+string(body))
+	})
+	defer server.Close()
+
+	if _, _, err := conn.CreateBucket("plain"); nil != err || 1 != len(requests) {
+		t.Fatalf("unexpected requests %v %v", requests, err)
+	}
+
+	conn.BucketLifecycle = &radosgwapi.LifecycleConfiguration{Rules: []radosgwapi.LifecycleRule{{
+		ID:                             "uploads",
+		Status:                         radosgwapi.LifecycleEnabled,
+		Filter:                         &radosgwapi.LifecycleFilter{},
+		AbortIncompleteMultipartUpload: &radosgwapi.AbortIncompleteMultipartUpload{DaysAfterInitiation: 1},
+	}}}
+	if _, _, err := conn.CreateBucket("pictures"); nil != err || 3 != len(requests) {
+		t.Fatalf("unexpected requests %v %v", requests, err)
+	}
+	if !strings.HasPrefix(requests[1], "PUT /pictures? ") ||
+		!strings.HasPrefix(requests[2], "PUT /pictures?lifecycle= <LifecycleConfiguration><Rule><ID>uploads</ID>") {
+		t.Errorf("unexpected requests %v", requests)
+	}
+}
+
+func TestCreateBucketLifecycleFailure(t *testing.T) {
+	conn, server := newTestConnection(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Query()["lifecycle"] != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			w.Write([]byte(`<Error><Code>MalformedXML</Code></Error>`))
+		}
+	})
+	defer server.Close()
+
+	conn.BucketLifecycle = &radosgwapi.LifecycleConfiguration{}
+	body, statusCode, err := conn.CreateBucket("pictures")
+	if nil == err || http.StatusBadRequest != statusCode || `<Error><Code>MalformedXML</Code></Error>` != string(body) {
+		t.Errorf("unexpected response %d %s %v", statusCode, body, err)
+	}
+}
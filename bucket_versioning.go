@@ -0,0 +1,213 @@
+package radosgwapi
+
+import (
+	"bytes"
+	"context"
+	"encoding/xml"
+	"errors"
+	"net/http"
+	"net/url"
+	"strconv"
+)
+
+// Bucket versioning states. A bucket that never had versioning enabled
+// reports an empty Status.
+const (
+	VersioningEnabled   = "Enabled"
+	VersioningSuspended = "Suspended"
+
+	MFADeleteEnabled  = "Enabled"
+	MFADeleteDisabled = "Disabled"
+)
+
+// BucketVersioningConfig holds the parameters of PutBucketVersioning.
+// Changing MFADelete requires MFA, the "serial-number token" of the root
+// account's device.
+type BucketVersioningConfig struct {
+	Bucket    string
+	Status    string
+	MFADelete string
+	MFA       string
+}
+
+func (conn *Connection) GetBucketVersioning(bucket string) (result *VersioningConfiguration, err error) {
+	return conn.GetBucketVersioningWithContext(context.Background(), bucket)
+}
+
+func (conn *Connection) GetBucketVersioningWithContext(ctx context.Context, bucket string) (result *VersioningConfiguration, err error) {
+	args := url.Values{}
+	args.Add("versioning", "")
+
+	_, _, body, err := conn.RequestWithContext(ctx, "GET", "/"+bucket, args, nil)
+	if nil != err {
+		return
+	}
+
+	result = &VersioningConfiguration{}
+	err = xml.Unmarshal(body, result)
+	return
+}
+
+func (conn *Connection) PutBucketVersioning(versioningCfg *BucketVersioningConfig) (err error) {
+	return conn.PutBucketVersioningWithContext(context.Background(), versioningCfg)
+}
+
+func (conn *Connection) PutBucketVersioningWithContext(ctx context.Context, versioningCfg *BucketVersioningConfig) (err error) {
+	putBody, err := xml.Marshal(&VersioningConfiguration{Status: versioningCfg.Status, MfaDelete: versioningCfg.MFADelete})
+	if nil != err {
+		return
+	}
+
+	args := url.Values{}
+	args.Add("versioning", "")
+
+	reqHeader := http.Header{}
+	if "" != versioningCfg.MFA {
+		reqHeader.Set("X-Amz-Mfa", versioningCfg.MFA)
+	}
+
+	_, _, _, err = conn.requestWithHeader(ctx, "PUT", "/"+versioningCfg.Bucket, args, reqHeader, bytes.NewReader(putBody))
+	return
+}
+
+// IsDeleteMarker reports whether the entry is a delete marker rather than a
+// version of the object.
+func (version *ObjectVersion) IsDeleteMarker() bool {
+	return "DeleteMarker" == version.XMLName.Local
+}
+
+// ListObjectVersionsConfig holds the parameters of ListObjectVersions.
+// MaxKeys of 0 leaves the server default of 1000.
+type ListObjectVersionsConfig struct {
+	Bucket          string
+	Prefix          string
+	Delimiter       string
+	KeyMarker       string
+	VersionIdMarker string
+	MaxKeys         int
+}
+
+func (listCfg *ListObjectVersionsConfig) args() url.Values {
+	args := url.Values{}
+	args.Add("versions", "")
+
+	addString := func(key, value string) {
+		if "" != value {
+			args.Add(key, value)
+		}
+	}
+	addString("prefix", listCfg.Prefix)
+	addString("delimiter", listCfg.Delimiter)
+	addString("key-marker", listCfg.KeyMarker)
+	addString("version-id-marker", listCfg.VersionIdMarker)
+	if listCfg.MaxKeys > 0 {
+		args.Add("max-keys", strconv.Itoa(listCfg.MaxKeys))
+	}
+	return args
+}
+
+func (conn *Connection) ListObjectVersions(listCfg *ListObjectVersionsConfig) (result *ListVersionsResult, err error) {
+	return conn.ListObjectVersionsWithContext(context.Background(), listCfg)
+}
+
+// ListObjectVersionsWithContext returns one page of the versions and delete
+// markers in the bucket, ordered by key and then newest first.
+func (conn *Connection) ListObjectVersionsWithContext(ctx context.Context, listCfg *ListObjectVersionsConfig) (result *ListVersionsResult, err error) {
+	_, _, body, err := conn.RequestWithContext(ctx, "GET", "/"+listCfg.Bucket, listCfg.args(), nil)
+	if nil != err {
+		return
+	}
+
+	result = &ListVersionsResult{}
+	err = xml.Unmarshal(body, result)
+	if nil != err {
+		return
+	}
+
+	// Versions catches every unknown element, such as EncodingType.
+	versions := result.Versions[:0]
+	for _, version := range result.Versions {
+		if "Version" == version.XMLName.Local || version.IsDeleteMarker() {
+			versions = append(versions, version)
+		}
+	}
+	result.Versions = versions
+	return
+}
+
+// nextPage moves listCfg past page. It fails when the truncated page gives
+// no new position to continue from.
+func (listCfg *ListObjectVersionsConfig) nextPage(page *ListVersionsResult) error {
+	if "" == page.NextKeyMarker && "" == page.NextVersionIdMarker ||
+		page.NextKeyMarker == listCfg.KeyMarker && page.NextVersionIdMarker == listCfg.VersionIdMarker {
+		return errors.New("radosgwapi: truncated version listing without a new marker")
+	}
+	listCfg.KeyMarker = page.NextKeyMarker
+	listCfg.VersionIdMarker = page.NextVersionIdMarker
+	return nil
+}
+
+func (conn *Connection) ListObjectVersionsPages(listCfg *ListObjectVersionsConfig, fn func(page *ListVersionsResult) bool) error {
+	return conn.ListObjectVersionsPagesWithContext(context.Background(), listCfg, fn)
+}
+
+// ListObjectVersionsPagesWithContext calls fn with every page of the listing,
+// starting from listCfg, until the listing is exhausted or fn returns false.
+func (conn *Connection) ListObjectVersionsPagesWithContext(ctx context.Context, listCfg *ListObjectVersionsConfig, fn func(page *ListVersionsResult) bool) error {
+	pageCfg := *listCfg
+	for {
+		page, err := conn.ListObjectVersionsWithContext(ctx, &pageCfg)
+		if nil != err {
+			return err
+		}
+
+		if !fn(page) || !page.IsTruncated {
+			return nil
+		}
+		if err = pageCfg.nextPage(page); nil != err {
+			return err
+		}
+	}
+}
+
+// VersionIterator walks the versions and delete markers of a listing,
+// fetching pages on demand:
+//
+//	it := conn.NewVersionIterator(ctx, &ListObjectVersionsConfig{Bucket: "b"})
+//	for it.Next() {
+//		version := it.Version()
+//	}
+//	err := it.Err()
+type VersionIterator struct {
+	pageIterator
+	page     *ListVersionsResult
+	prefixes []CommonPrefix
+}
+
+func (conn *Connection) NewVersionIterator(ctx context.Context, listCfg *ListObjectVersionsConfig) *VersionIterator {
+	it := &VersionIterator{}
+	pageCfg := *listCfg
+	it.fetch = func() (int, bool, error) {
+		page, err := conn.ListObjectVersionsWithContext(ctx, &pageCfg)
+		if nil != err {
+			return 0, false, err
+		}
+		it.page = page
+		it.prefixes = append(it.prefixes, page.CommonPrefixes...)
+		return len(page.Versions), page.IsTruncated, nil
+	}
+	it.advance = func() error {
+		return pageCfg.nextPage(it.page)
+	}
+	return it
+}
+
+// Version returns the current version or delete marker.
+func (it *VersionIterator) Version() ObjectVersion {
+	return it.page.Versions[it.idx]
+}
+
+// CommonPrefixes returns the common prefixes of the pages fetched so far.
+func (it *VersionIterator) CommonPrefixes() []CommonPrefix {
+	return it.prefixes
+}
@@ -0,0 +1,52 @@
+package radosgwapi_test
+
+import (
+	"context"
+	"errors"
+	"io/ioutil"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	radosgwapi "github.com/changjixiong/radosgw-api"
+)
+
+func TestPutObjectByPicCanceled(t *testing.T) {
+	aborted := make(chan string, 1)
+	conn, server := newTestConnection(func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case "POST" == r.Method && r.URL.Query()["uploads"] != nil:
+			w.Write([]byte(`<InitiateMultipartUploadResult><Bucket>b</Bucket><Key>k</Key><UploadId>upload-1</UploadId></InitiateMultipartUploadResult>`))
+		case "PUT" == r.Method:
+			ioutil.ReadAll(r.Body)
+			<-r.Context().Done()
+		case "DELETE" == r.Method:
+			aborted <- r.URL.Query().Get("uploadId")
+			w.WriteHeader(http.StatusNoContent)
+		}
+	})
+	defer server.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+	defer cancel()
+
+	_, _, err := conn.PutObjectByPicWithContext(ctx, &radosgwapi.ObjectConfig{
+		Bucket:       "b",
+		Key:          "k",
+		ObjectReader: strings.NewReader("content"),
+	})
+
+	if !errors.Is(err, context.DeadlineExceeded) || !errors.Is(err, radosgwapi.ErrRequestCanceled) {
+		t.Fatalf("expected canceled error wrapping context.DeadlineExceeded, got %v", err)
+	}
+
+	select {
+	case uploadId := <-aborted:
+		if "upload-1" != uploadId {
+			t.Errorf("aborted upload %q", uploadId)
+		}
+	case <-time.After(time.Second):
+		t.Error("multipart upload was not aborted")
+	}
+}
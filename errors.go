@@ -0,0 +1,136 @@
+package radosgwapi
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"encoding/xml"
+	"fmt"
+	"net/http"
+	"strings"
+)
+
+// Error is returned as err for every non-2xx response. It carries the fields
+// of the S3 <Error> XML document, or of the admin API's JSON error form.
+type Error struct {
+	StatusCode int    `xml:"-" json:"-"`
+	Code       string `xml:"Code" json:"Code"`
+	Message    string `xml:"Message" json:"Message"`
+	Resource   string `xml:"Resource" json:"Resource"`
+	BucketName string `xml:"BucketName" json:"BucketName"`
+	Key        string `xml:"Key" json:"Key"`
+	RequestId  string `xml:"RequestId" json:"RequestId"`
+	HostId     string `xml:"HostId" json:"HostId"`
+
+	// Err is the underlying cause, such as ctx.Err() for a cancelled request.
+	Err error `xml:"-" json:"-"`
+
+	// body is the raw response, returned by the legacy []byte APIs.
+	body []byte
+}
+
+// Sentinel errors for use with errors.Is. Only Code is compared.
+var (
+	ErrAccessDenied          = &Error{Code: "AccessDenied"}
+	ErrBucketAlreadyExists   = &Error{Code: "BucketAlreadyExists"}
+	ErrBucketNotEmpty        = &Error{Code: "BucketNotEmpty"}
+	ErrInvalidAccessKeyId    = &Error{Code: "InvalidAccessKeyId"}
+	ErrNoSuchBucket          = &Error{Code: "NoSuchBucket"}
+	ErrNoSuchKey             = &Error{Code: "NoSuchKey"}
+	ErrNoSuchLifecycle       = &Error{Code: "NoSuchLifecycleConfiguration"}
+	ErrNoSuchUpload          = &Error{Code: "NoSuchUpload"}
+	ErrNoSuchUser            = &Error{Code: "NoSuchUser"}
+	ErrSignatureDoesNotMatch = &Error{Code: "SignatureDoesNotMatch"}
+	ErrNotModified           = &Error{Code: "NotModified"}
+	ErrPreconditionFailed    = &Error{Code: "PreconditionFailed"}
+	ErrRequestCanceled       = &Error{Code: "RequestCanceled"}
+)
+
+func (e *Error) Error() string {
+	msg := fmt.Sprintf("radosgwapi: %s (status %d)", e.Code, e.StatusCode)
+	if "" != e.Message {
+		msg += ": " + e.Message
+	}
+	if "" != e.RequestId {
+		msg += ", request id " + e.RequestId
+	}
+	if nil != e.Err {
+		msg += ": " + e.Err.Error()
+	}
+	return msg
+}
+
+func (e *Error) Unwrap() error {
+	return e.Err
+}
+
+// Is reports whether target is an *Error with the same Code, so that
+// errors.Is(err, ErrNoSuchBucket) works regardless of message or request id.
+func (e *Error) Is(target error) bool {
+	t, ok := target.(*Error)
+	if !ok || nil == t {
+		return false
+	}
+	return "" != t.Code && t.Code == e.Code
+}
+
+// statusCodes maps responses that carry no body (HEAD, 304) to a code.
+var statusCodes = map[int]string{
+	http.StatusNotModified:        "NotModified",
+	http.StatusBadRequest:         "BadRequest",
+	http.StatusForbidden:          "Forbidden",
+	http.StatusNotFound:           "NotFound",
+	http.StatusConflict:           "Conflict",
+	http.StatusPreconditionFailed: "PreconditionFailed",
+}
+
+func newError(statusCode int, header http.Header, body []byte) *Error {
+	e := &Error{}
+
+	trimmed := bytes.TrimSpace(body)
+	if len(trimmed) > 0 {
+		if '{' == trimmed[0] {
+			json.Unmarshal(trimmed, e)
+		} else {
+			xml.Unmarshal(trimmed, e)
+		}
+	}
+
+	e.StatusCode = statusCode
+	e.body = body
+	if "" == e.RequestId {
+		e.RequestId = header.Get("X-Amz-Request-Id")
+	}
+	if "" == e.Code {
+		e.Code = statusCodes[statusCode]
+	}
+	if "" == e.Code {
+		e.Code = strings.Replace(http.StatusText(statusCode), " ", "", -1)
+	}
+	if "" == e.Message && len(trimmed) > 0 && '<' != trimmed[0] && '{' != trimmed[0] {
+		e.Message = string(trimmed)
+	}
+
+	return e
+}
+
+// contextError wraps ctx.Err() in an *Error when err was caused by ctx being
+// done, and returns err unchanged otherwise.
+func contextError(ctx context.Context, err error) error {
+	if nil == ctx.Err() {
+		return err
+	}
+	return &Error{Code: "RequestCanceled", Err: ctx.Err()}
+}
+
+// errorDocument returns the error carried by a 200 response, as sent by
+// copy and complete-multipart requests that fail after the status line.
+func errorDocument(statusCode int, header http.Header, body []byte) error {
+	probe := &struct {
+		XMLName xml.Name
+	}{}
+	if nil != xml.Unmarshal(body, probe) || "Error" != probe.XMLName.Local {
+		return nil
+	}
+	return newError(statusCode, header, body)
+}
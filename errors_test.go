@@ -0,0 +1,58 @@
+package radosgwapi_test
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	radosgwapi "github.com/changjixiong/radosgw-api"
+)
+
+func newTestConnection(handler http.HandlerFunc) (*radosgwapi.Connection, *httptest.Server) {
+	server := httptest.NewServer(handler)
+	return radosgwapi.NewConnection(server.URL, "MyID", "MyKey", http.Header{}), server
+}
+
+func TestErrorResponses(t *testing.T) {
+	cases := []struct {
+		status   int
+		body     string
+		sentinel error
+		code     string
+	}{
+		{http.StatusNotFound, `<?xml version="1.0" encoding="UTF-8"?><Error><Code>NoSuchBucket</Code><BucketName>nb</BucketName><RequestId>tx1</RequestId><HostId>h1</HostId></Error>`, radosgwapi.ErrNoSuchBucket, "NoSuchBucket"},
+		{http.StatusConflict, `<Error><Code>BucketAlreadyExists</Code></Error>`, radosgwapi.ErrBucketAlreadyExists, "BucketAlreadyExists"},
+		{http.StatusForbidden, `{"Code":"AccessDenied","RequestId":"tx2","HostId":"h2"}`, radosgwapi.ErrAccessDenied, "AccessDenied"},
+		{http.StatusNotFound, ``, nil, "NotFound"},
+	}
+
+	for i, tc := range cases {
+		conn, server := newTestConnection(func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(tc.status)
+			w.Write([]byte(tc.body))
+		})
+
+		body, statusCode, err := conn.CreateBucket("nb")
+		server.Close()
+
+		if statusCode != tc.status || string(body) != tc.body {
+			t.Errorf("case %d: got status %d body %q", i, statusCode, body)
+		}
+
+		var rgwErr *radosgwapi.Error
+		if !errors.As(err, &rgwErr) {
+			t.Errorf("case %d: expected *radosgwapi.Error, got %v", i, err)
+			continue
+		}
+		if rgwErr.Code != tc.code || rgwErr.StatusCode != tc.status {
+			t.Errorf("case %d: got code %q status %d", i, rgwErr.Code, rgwErr.StatusCode)
+		}
+		if nil != tc.sentinel && !errors.Is(err, tc.sentinel) {
+			t.Errorf("case %d: errors.Is(%v, %v) is false", i, err, tc.sentinel)
+		}
+		if errors.Is(err, radosgwapi.ErrNoSuchKey) {
+			t.Errorf("case %d: unexpected match with ErrNoSuchKey", i)
+		}
+	}
+}
@@ -0,0 +1,217 @@
+package radosgwapi
+
+import (
+	"context"
+	"encoding/xml"
+	"errors"
+	"net/url"
+	"strconv"
+)
+
+// ListObjectsConfig holds the parameters of ListObjects and ListObjectsV2.
+// Marker is only used by V1; ContinuationToken, StartAfter and FetchOwner
+// only by V2. MaxKeys of 0 leaves the server default of 1000.
+type ListObjectsConfig struct {
+	Bucket            string
+	Prefix            string
+	Delimiter         string
+	Marker            string
+	ContinuationToken string
+	StartAfter        string
+	MaxKeys           int
+	FetchOwner        bool
+}
+
+func (listCfg *ListObjectsConfig) args(v2 bool) url.Values {
+	args := url.Values{}
+
+	addString := func(key, value string) {
+		if "" != value {
+			args.Add(key, value)
+		}
+	}
+	addString("prefix", listCfg.Prefix)
+	addString("delimiter", listCfg.Delimiter)
+	if listCfg.MaxKeys > 0 {
+		args.Add("max-keys", strconv.Itoa(listCfg.MaxKeys))
+	}
+
+	if !v2 {
+		addString("marker", listCfg.Marker)
+		return args
+	}
+
+	args.Add("list-type", "2")
+	addString("continuation-token", listCfg.ContinuationToken)
+	addString("start-after", listCfg.StartAfter)
+	if listCfg.FetchOwner {
+		args.Add("fetch-owner", "true")
+	}
+	return args
+}
+
+func (conn *Connection) ListObjects(listCfg *ListObjectsConfig) (result *ListBucketResult, err error) {
+	return conn.ListObjectsWithContext(context.Background(), listCfg)
+}
+
+func (conn *Connection) ListObjectsWithContext(ctx context.Context, listCfg *ListObjectsConfig) (result *ListBucketResult, err error) {
+	return conn.listObjects(ctx, listCfg, false)
+}
+
+func (conn *Connection) ListObjectsV2(listCfg *ListObjectsConfig) (result *ListBucketResult, err error) {
+	return conn.ListObjectsV2WithContext(context.Background(), listCfg)
+}
+
+func (conn *Connection) ListObjectsV2WithContext(ctx context.Context, listCfg *ListObjectsConfig) (result *ListBucketResult, err error) {
+	return conn.listObjects(ctx, listCfg, true)
+}
+
+func (conn *Connection) listObjects(ctx context.Context, listCfg *ListObjectsConfig, v2 bool) (result *ListBucketResult, err error) {
+	_, _, body, err := conn.RequestWithContext(ctx, "GET", "/"+listCfg.Bucket, listCfg.args(v2), nil)
+	if nil != err {
+		return
+	}
+
+	result = &ListBucketResult{}
+	err = xml.Unmarshal(body, result)
+	return
+}
+
+// nextPage moves listCfg past page. It fails when the truncated page gives
+// no position to continue from, which would otherwise restart the listing.
+func (listCfg *ListObjectsConfig) nextPage(page *ListBucketResult, v2 bool) error {
+	if v2 {
+		if "" == page.NextContinuationToken || page.NextContinuationToken == listCfg.ContinuationToken {
+			return errors.New("radosgwapi: truncated listing without a new continuation token")
+		}
+		listCfg.ContinuationToken = page.NextContinuationToken
+		return nil
+	}
+
+	// NextMarker is only returned when a delimiter is given.
+	marker := page.NextMarker
+	if "" == marker {
+		if n := len(page.Contents); n > 0 {
+			marker = page.Contents[n-1].Key
+		}
+		if n := len(page.CommonPrefixes); n > 0 && page.CommonPrefixes[n-1].Prefix > marker {
+			marker = page.CommonPrefixes[n-1].Prefix
+		}
+	}
+	if "" == marker || marker == listCfg.Marker {
+		return errors.New("radosgwapi: truncated listing without a new marker")
+	}
+	listCfg.Marker = marker
+	return nil
+}
+
+func (conn *Connection) ListObjectsPages(listCfg *ListObjectsConfig, v2 bool, fn func(page *ListBucketResult) bool) error {
+	return conn.ListObjectsPagesWithContext(context.Background(), listCfg, v2, fn)
+}
+
+// ListObjectsPagesWithContext calls fn with every page of the listing,
+// starting from listCfg, until the listing is exhausted or fn returns false.
+func (conn *Connection) ListObjectsPagesWithContext(ctx context.Context, listCfg *ListObjectsConfig, v2 bool, fn func(page *ListBucketResult) bool) error {
+	pageCfg := *listCfg
+	for {
+		page, err := conn.listObjects(ctx, &pageCfg, v2)
+		if nil != err {
+			return err
+		}
+
+		if !fn(page) || !page.IsTruncated {
+			return nil
+		}
+		if err = pageCfg.nextPage(page, v2); nil != err {
+			return err
+		}
+	}
+}
+
+// pageIterator is the pagination state shared by the listing iterators.
+// fetch gets the page at the current position and returns its number of
+// entries; advance moves the position past it.
+type pageIterator struct {
+	fetch     func() (n int, truncated bool, err error)
+	advance   func() error
+	started   bool
+	n         int
+	truncated bool
+	idx       int
+	err       error
+}
+
+// Next advances to the next entry, fetching the next page when needed. It
+// returns false at the end of the listing or on error.
+func (it *pageIterator) Next() bool {
+	if nil != it.err {
+		return false
+	}
+
+	it.idx++
+	for !it.started || it.idx >= it.n {
+		if it.started {
+			if !it.truncated {
+				return false
+			}
+			if err := it.advance(); nil != err {
+				it.err = err
+				return false
+			}
+		}
+
+		n, truncated, err := it.fetch()
+		if nil != err {
+			it.err = err
+			return false
+		}
+		it.started, it.n, it.truncated, it.idx = true, n, truncated, 0
+	}
+
+	return true
+}
+
+func (it *pageIterator) Err() error {
+	return it.err
+}
+
+// ObjectIterator walks the objects of a listing, fetching pages on demand:
+//
+//	it := conn.NewObjectIterator(ctx, &ListObjectsConfig{Bucket: "b"}, true)
+//	for it.Next() {
+//		obj := it.Object()
+//	}
+//	err := it.Err()
+type ObjectIterator struct {
+	pageIterator
+	page     *ListBucketResult
+	prefixes []CommonPrefix
+}
+
+func (conn *Connection) NewObjectIterator(ctx context.Context, listCfg *ListObjectsConfig, v2 bool) *ObjectIterator {
+	it := &ObjectIterator{}
+	pageCfg := *listCfg
+	it.fetch = func() (int, bool, error) {
+		page, err := conn.listObjects(ctx, &pageCfg, v2)
+		if nil != err {
+			return 0, false, err
+		}
+		it.page = page
+		it.prefixes = append(it.prefixes, page.CommonPrefixes...)
+		return len(page.Contents), page.IsTruncated, nil
+	}
+	it.advance = func() error {
+		return pageCfg.nextPage(it.page, v2)
+	}
+	return it
+}
+
+// Object returns the current object.
+func (it *ObjectIterator) Object() ObjectInfo {
+	return it.page.Contents[it.idx]
+}
+
+// CommonPrefixes returns the common prefixes of the pages fetched so far.
+func (it *ObjectIterator) CommonPrefixes() []CommonPrefix {
+	return it.prefixes
+}
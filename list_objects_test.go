@@ -0,0 +1,132 @@
+package radosgwapi_test
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"testing"
+
+	radosgwapi "github.com/changjixiong/radosgw-api"
+)
+
+// listingHandler serves a V1 or V2 listing of keys, maxKeys at a time.
+func listingHandler(keys []string, requests *[]string) http.HandlerFunc {
+	sort.Strings(keys)
+	return func(w http.ResponseWriter, r *http.Request) {
+		query := r.URL.Query()
+		*requests = append(*requests, r.URL.RawQuery)
+
+		maxKeys, _ := strconv.Atoi(query.Get("max-keys"))
+		after := query.Get("marker")
+		if "2" == query.Get("list-type") {
+			after = query.Get("continuation-token")
+		}
+
+		start := sort.SearchStrings(keys, after)
+		if start < len(keys) && keys[start] == after {
+			start++
+		}
+		end := start + maxKeys
+		if end > len(keys) {
+			end = len(keys)
+		}
+
+		contents := ""
+		for _, key := range keys[start:end] {
+			contents += fmt.Sprintf("<Contents><Key>%s</Key><LastModified>2020-01-01T00:00:00.000Z</LastModified>"+
+				"<ETag>&quot;abc&quot;</ETag><Size>3</Size><StorageClass>STANDARD</StorageClass></Contents>", key)
+		}
+		truncated := end < len(keys)
+		token := ""
+		if truncated && "2" == query.Get("list-type") {
+			token = "<NextContinuationToken>" + keys[end-1] + "</NextContinuationToken>"
+		}
+
+		fmt.Fprintf(w, `<?xml version="1.0" encoding="UTF-8"?><ListBucketResult xmlns="http://s3.amazonaws.com/doc/2006-03-01/">`+
+			`<Name>b</Name><Prefix>%s</Prefix><MaxKeys>%d</MaxKeys><IsTruncated>%t</IsTruncated>%s%s</ListBucketResult>`,
+			query.Get("prefix"), maxKeys, truncated, token, contents)
+	}
+}
+
+func TestObjectIterator(t *testing.T) {
+	keys := []string{}
+	for i := 0; i < 25; i++ {
+		keys = append(keys, fmt.Sprintf("pic/%03d.jpg", i))
+	}
+
+	for _, v2 := range []bool{false, true} {
+		requests := []string{}
+		conn, server := newTestConnection(listingHandler(keys, &requests))
+
+		it := conn.NewObjectIterator(context.Background(), &radosgwapi.ListObjectsConfig{Bucket: "b", Prefix: "pic/", MaxKeys: 10}, v2)
+		got := []string{}
+		for it.Next() {
+			got = append(got, it.Object().Key)
+		}
+		server.Close()
+
+		if nil != it.Err() {
+			t.Fatal(it.Err())
+		}
+		if strings.Join(keys, ",") != strings.Join(got, ",") {
+			t.Errorf("v2=%t: got keys %v", v2, got)
+		}
+		if 3 != len(requests) {
+			t.Errorf("v2=%t: expected 3 requests, got %v", v2, requests)
+		}
+		if v2 && !strings.Contains(requests[1], "continuation-token=pic%2F009.jpg") {
+			t.Errorf("unexpected v2 request %s", requests[1])
+		}
+		if !v2 && !strings.Contains(requests[1], "marker=pic%2F009.jpg") {
+			t.Errorf("unexpected v1 request %s", requests[1])
+		}
+	}
+}
+
+func TestListObjectsV2(t *testing.T) {
+	requests := []string{}
+	conn, server := newTestConnection(listingHandler([]string{"a", "b"}, &requests))
+	defer server.Close()
+
+	result, err := conn.ListObjectsV2(&radosgwapi.ListObjectsConfig{Bucket: "b", MaxKeys: 1, FetchOwner: true})
+	if nil != err {
+		t.Fatal(err)
+	}
+	if !result.IsTruncated || "a" != result.NextContinuationToken || 1 != len(result.Contents) ||
+		`"abc"` != result.Contents[0].ETag || 3 != result.Contents[0].Size || 2020 != result.Contents[0].LastModified.Year() {
+		t.Errorf("unexpected result %+v", result)
+	}
+	if !strings.Contains(requests[0], "fetch-owner=true") || !strings.Contains(requests[0], "list-type=2") {
+		t.Errorf("unexpected request %s", requests[0])
+	}
+}
+
+func TestListingWithoutProgress(t *testing.T) {
+	var requests int
+	conn, server := newTestConnection(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		w.Write([]byte(`<ListBucketResult><IsTruncated>true</IsTruncated></ListBucketResult>`))
+	})
+	defer server.Close()
+
+	for _, v2 := range []bool{false, true} {
+		requests = 0
+		it := conn.NewObjectIterator(context.Background(), &radosgwapi.ListObjectsConfig{Bucket: "b"}, v2)
+		for it.Next() {
+		}
+		if nil == it.Err() || 1 != requests {
+			t.Errorf("v2 %t: expected error after one request, got %v after %d", v2, it.Err(), requests)
+		}
+
+		requests = 0
+		err := conn.ListObjectsPages(&radosgwapi.ListObjectsConfig{Bucket: "b"}, v2, func(page *radosgwapi.ListBucketResult) bool {
+			return true
+		})
+		if nil == err || 1 != requests {
+			t.Errorf("v2 %t: expected error after one request, got %v after %d", v2, err, requests)
+		}
+	}
+}
@@ -0,0 +1,336 @@
+package radosgwapi
+
+import (
+	"bytes"
+	"context"
+	"crypto/md5"
+	"encoding/base64"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"sort"
+	"strconv"
+	"sync"
+)
+
+// Multipart upload limits.
+const (
+	MinPartSize = 5 << 20
+	MaxPartSize = 5 << 30
+	MaxParts    = 10000
+)
+
+func (conn *Connection) initiateMultipartUpload(ctx context.Context, bucket, key string, reqHeader http.Header) (result *InitiateMultipartUploadResult, err error) {
+	args := url.Values{}
+	args.Add("uploads", "")
+
+	_, _, body, err := conn.requestWithHeader(ctx, "POST", objectPath(bucket, key), args, reqHeader, nil)
+	if nil != err {
+		return
+	}
+
+	result = &InitiateMultipartUploadResult{}
+	err = xml.Unmarshal(body, result)
+	return
+}
+
+func (conn *Connection) completeMultipartUpload(ctx context.Context, bucket, key, uploadId string, parts []CompletedPart) (result *CompleteMultipartUploadResult, err error) {
+	_, _, body, err := conn.completeMultipartUploadResponse(ctx, bucket, key, uploadId, parts)
+	if nil != err {
+		return
+	}
+
+	result = &CompleteMultipartUploadResult{}
+	err = xml.Unmarshal(body, result)
+	return
+}
+
+// completeMultipartUploadResponse returns the raw response of the complete
+// request.
+func (conn *Connection) completeMultipartUploadResponse(ctx context.Context, bucket, key, uploadId string, parts []CompletedPart) (statusCode int, header http.Header, body []byte, err error) {
+	postBody, err := xml.Marshal(&CompleteMultipartUpload{Parts: parts})
+	if nil != err {
+		return
+	}
+
+	args := url.Values{}
+	args.Add("uploadId", uploadId)
+
+	statusCode, header, body, err = conn.RequestWithContext(ctx, "POST", objectPath(bucket, key), args, bytes.NewReader(postBody))
+	if nil != err {
+		return
+	}
+
+	// The request may fail after the 200 status has been sent, in which case
+	// the body is an error document.
+	err = errorDocument(statusCode, header, body)
+	return
+}
+
+func (conn *Connection) abortMultipartUpload(ctx context.Context, bucket, key, uploadId string) (err error) {
+	args := url.Values{}
+	args.Add("uploadId", uploadId)
+
+	_, _, _, err = conn.RequestWithContext(ctx, "DELETE", objectPath(bucket, key), args, nil)
+	return
+}
+
+func (conn *Connection) InitiateMultipartUpload(bucket, key string, putOpts *PutObjectOptions) (result *InitiateMultipartUploadResult, err error) {
+	return conn.InitiateMultipartUploadWithContext(context.Background(), bucket, key, putOpts)
+}
+
+// InitiateMultipartUploadWithContext starts a multipart upload of key. putOpts
+// may be nil; its ContentMD5 is ignored, as it only applies to single parts.
+func (conn *Connection) InitiateMultipartUploadWithContext(ctx context.Context, bucket, key string, putOpts *PutObjectOptions) (result *InitiateMultipartUploadResult, err error) {
+	if nil == putOpts {
+		putOpts = &PutObjectOptions{}
+	}
+	initHeader := putOpts.header()
+	initHeader.Del("Content-Md5")
+
+	return conn.initiateMultipartUpload(ctx, bucket, key, initHeader)
+}
+
+// UploadPartConfig holds the parameters of UploadPart. PartNumber runs from
+// 1 to MaxParts; every part but the last must be at least MinPartSize.
+type UploadPartConfig struct {
+	Bucket     string
+	Key        string
+	UploadId   string
+	PartNumber int
+	Data       []byte
+}
+
+func (conn *Connection) UploadPart(partCfg *UploadPartConfig) (part *CompletedPart, err error) {
+	return conn.UploadPartWithContext(context.Background(), partCfg)
+}
+
+// UploadPartWithContext uploads partCfg.Data with its Content-MD5 and returns
+// the part as it should be passed to CompleteMultipartUpload.
+func (conn *Connection) UploadPartWithContext(ctx context.Context, partCfg *UploadPartConfig) (part *CompletedPart, err error) {
+	if partCfg.PartNumber < 1 || partCfg.PartNumber > MaxParts {
+		return nil, fmt.Errorf("radosgwapi: part number %d out of range 1-%d", partCfg.PartNumber, MaxParts)
+	}
+
+	etag, err := conn.uploadPart(ctx, partCfg.Bucket, partCfg.Key, partCfg.UploadId, partCfg.PartNumber, partCfg.Data)
+	if nil != err {
+		return
+	}
+	return &CompletedPart{PartNumber: partCfg.PartNumber, ETag: etag}, nil
+}
+
+func (conn *Connection) CompleteMultipartUpload(bucket, key, uploadId string, parts []CompletedPart) (result *CompleteMultipartUploadResult, err error) {
+	return conn.CompleteMultipartUploadWithContext(context.Background(), bucket, key, uploadId, parts)
+}
+
+// CompleteMultipartUploadWithContext assembles the object from parts, which
+// are sent in ascending part number order as S3 requires.
+func (conn *Connection) CompleteMultipartUploadWithContext(ctx context.Context, bucket, key, uploadId string, parts []CompletedPart) (result *CompleteMultipartUploadResult, err error) {
+	sorted := append([]CompletedPart(nil), parts...)
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i].PartNumber < sorted[j].PartNumber })
+
+	return conn.completeMultipartUpload(ctx, bucket, key, uploadId, sorted)
+}
+
+func (conn *Connection) AbortMultipartUpload(bucket, key, uploadId string) (err error) {
+	return conn.AbortMultipartUploadWithContext(context.Background(), bucket, key, uploadId)
+}
+
+// AbortMultipartUploadWithContext aborts the upload and frees its parts.
+func (conn *Connection) AbortMultipartUploadWithContext(ctx context.Context, bucket, key, uploadId string) (err error) {
+	return conn.abortMultipartUpload(ctx, bucket, key, uploadId)
+}
+
+// MultipartUploader uploads an ObjectConfig as a multipart upload. Up to
+// Concurrency parts are in flight at once, each in its own reused buffer, so
+// memory stays within Concurrency * PartSize. Any failure aborts the upload.
+type MultipartUploader struct {
+	conn *Connection
+
+	// PartSize is the size of every part but the last. It defaults to
+	// MinPartSize and is raised when ObjectConfig.PicSize is set and would
+	// need more than MaxParts parts.
+	PartSize int64
+
+	// Concurrency is the number of parts uploaded in parallel, 1 by default.
+	Concurrency int
+}
+
+func (conn *Connection) NewMultipartUploader(partSize int64) *MultipartUploader {
+	return &MultipartUploader{
+		conn:        conn,
+		PartSize:    partSize,
+		Concurrency: 1,
+	}
+}
+
+func (uploader *MultipartUploader) Upload(objectCfg *ObjectConfig) (result *CompleteMultipartUploadResult, err error) {
+	return uploader.UploadWithContext(context.Background(), objectCfg)
+}
+
+func (uploader *MultipartUploader) UploadWithContext(ctx context.Context, objectCfg *ObjectConfig) (result *CompleteMultipartUploadResult, err error) {
+	err = uploader.upload(ctx, objectCfg, func(uploadId string, parts []CompletedPart) (err error) {
+		result, err = uploader.conn.CompleteMultipartUploadWithContext(ctx, objectCfg.Bucket, objectCfg.Key, uploadId, parts)
+		return
+	})
+	return
+}
+
+// upload initiates the upload, uploads the parts and calls complete with
+// them. The upload is aborted if any step, complete included, fails.
+func (uploader *MultipartUploader) upload(ctx context.Context, objectCfg *ObjectConfig, complete func(uploadId string, parts []CompletedPart) error) (err error) {
+	partSize, err := adjustPartSize(int64(objectCfg.PicSize), uploader.PartSize, MinPartSize)
+	if nil != err {
+		return
+	}
+
+	initHeader := objectCfg.header()
+	initHeader.Del("Content-Md5")
+
+	upload, err := uploader.conn.initiateMultipartUpload(ctx, objectCfg.Bucket, objectCfg.Key, initHeader)
+	if nil != err {
+		return
+	}
+	defer func() {
+		if nil != err {
+			uploader.conn.abortMultipartUpload(context.Background(), objectCfg.Bucket, objectCfg.Key, upload.UploadId)
+		}
+	}()
+
+	// Parts are read in order until a short read; an empty object is
+	// uploaded as a single empty part.
+	partNumber, finished := 0, false
+	next := func(buf []byte) (int, int, error) {
+		if finished {
+			return 0, 0, nil
+		}
+		n, readErr := io.ReadFull(objectCfg.ObjectReader, buf)
+		if io.EOF == readErr && partNumber > 0 {
+			return 0, 0, nil
+		}
+		if nil != readErr && io.EOF != readErr && io.ErrUnexpectedEOF != readErr {
+			return 0, 0, readErr
+		}
+		partNumber++
+		if partNumber > MaxParts {
+			return 0, 0, fmt.Errorf("radosgwapi: object needs more than %d parts of %d bytes", MaxParts, partSize)
+		}
+		finished = nil != readErr
+		return partNumber, n, nil
+	}
+
+	parts := []CompletedPart{}
+	err = uploader.uploadParts(ctx, objectCfg.Bucket, objectCfg.Key, upload.UploadId, partSize, next, func(part CompletedPart) error {
+		parts = append(parts, part)
+		return nil
+	})
+	if nil != err {
+		return
+	}
+
+	sort.Slice(parts, func(i, j int) bool { return parts[i].PartNumber < parts[j].PartNumber })
+	return complete(upload.UploadId, parts)
+}
+
+// uploadParts uploads the parts returned by next, up to Concurrency at a
+// time, each from its own reused buffer of partSize bytes. next fills buf
+// with the next part and returns its number and length, or a part number of
+// 0 when there are no more parts. done is called, one at a time, after each
+// part is uploaded. The first error stops the upload and is returned.
+func (uploader *MultipartUploader) uploadParts(ctx context.Context, bucket, key, uploadId string, partSize int64,
+	next func(buf []byte) (partNumber, n int, err error), done func(part CompletedPart) error) error {
+	concurrency := uploader.Concurrency
+	if concurrency < 1 {
+		concurrency = 1
+	}
+
+	// partCtx is cancelled on the first failure to stop the parts in flight.
+	partCtx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	var wg sync.WaitGroup
+	var mu sync.Mutex
+	var uploadErr error
+	fail := func(e error) {
+		mu.Lock()
+		if nil == uploadErr {
+			uploadErr = e
+			cancel()
+		}
+		mu.Unlock()
+	}
+
+	buffers := make(chan []byte, concurrency)
+	allocated := 0
+	for {
+		var buf []byte
+		if allocated < concurrency {
+			buf = make([]byte, partSize)
+			allocated++
+		} else {
+			select {
+			case buf = <-buffers:
+			case <-partCtx.Done():
+			}
+		}
+		if nil == buf {
+			break
+		}
+
+		partNumber, n, err := next(buf)
+		if nil != err {
+			fail(err)
+			break
+		}
+		if 0 == partNumber {
+			break
+		}
+
+		wg.Add(1)
+		go func(partNumber int, buf []byte, n int) {
+			defer wg.Done()
+
+			etag, err := uploader.conn.uploadPart(partCtx, bucket, key, uploadId, partNumber, buf[:n])
+			buffers <- buf
+			if nil == err {
+				mu.Lock()
+				err = done(CompletedPart{PartNumber: partNumber, ETag: etag})
+				mu.Unlock()
+			}
+			if nil != err {
+				fail(err)
+			}
+		}(partNumber, buf, n)
+	}
+	wg.Wait()
+
+	if nil == uploadErr && nil != ctx.Err() {
+		return contextError(ctx, ctx.Err())
+	}
+	return uploadErr
+}
+
+// uploadPart uploads data as part partNumber with its Content-MD5, and
+// returns the part's ETag.
+func (conn *Connection) uploadPart(ctx context.Context, bucket, key, uploadId string, partNumber int, data []byte) (etag string, err error) {
+	args := url.Values{}
+	args.Add("partNumber", strconv.Itoa(partNumber))
+	args.Add("uploadId", uploadId)
+
+	sum := md5.Sum(data)
+	reqHeader := http.Header{}
+	reqHeader.Set("Content-Md5", base64.StdEncoding.EncodeToString(sum[:]))
+
+	_, header, _, err := conn.requestWithHeader(ctx, "PUT", objectPath(bucket, key), args, reqHeader, bytes.NewReader(data))
+	if nil != err {
+		return
+	}
+
+	etag = header.Get("Etag")
+	if "" == etag {
+		err = fmt.Errorf("radosgwapi: no ETag for part %d of upload %s", partNumber, uploadId)
+	}
+	return
+}
@@ -0,0 +1,172 @@
+package radosgwapi
+
+import (
+	"context"
+	"encoding/xml"
+	"errors"
+	"net/url"
+	"strconv"
+)
+
+// ListPartsConfig holds the parameters of ListParts. MaxParts of 0 leaves the
+// server default of 1000.
+type ListPartsConfig struct {
+	Bucket           string
+	Key              string
+	UploadId         string
+	PartNumberMarker int
+	MaxParts         int
+}
+
+func (listCfg *ListPartsConfig) args() url.Values {
+	args := url.Values{}
+	args.Add("uploadId", listCfg.UploadId)
+	if listCfg.PartNumberMarker > 0 {
+		args.Add("part-number-marker", strconv.Itoa(listCfg.PartNumberMarker))
+	}
+	if listCfg.MaxParts > 0 {
+		args.Add("max-parts", strconv.Itoa(listCfg.MaxParts))
+	}
+	return args
+}
+
+func (conn *Connection) ListParts(listCfg *ListPartsConfig) (result *ListPartsResult, err error) {
+	return conn.ListPartsWithContext(context.Background(), listCfg)
+}
+
+// ListPartsWithContext returns one page of the parts uploaded so far.
+func (conn *Connection) ListPartsWithContext(ctx context.Context, listCfg *ListPartsConfig) (result *ListPartsResult, err error) {
+	_, _, body, err := conn.RequestWithContext(ctx, "GET", objectPath(listCfg.Bucket, listCfg.Key), listCfg.args(), nil)
+	if nil != err {
+		return
+	}
+
+	result = &ListPartsResult{}
+	err = xml.Unmarshal(body, result)
+	return
+}
+
+// nextPage moves listCfg past page. It fails when the truncated page gives
+// no later position to continue from, rather than cutting the listing short.
+func (listCfg *ListPartsConfig) nextPage(page *ListPartsResult) error {
+	if page.NextPartNumberMarker <= listCfg.PartNumberMarker {
+		return errors.New("radosgwapi: truncated part listing without a new marker")
+	}
+	listCfg.PartNumberMarker = page.NextPartNumberMarker
+	return nil
+}
+
+func (conn *Connection) ListPartsPages(listCfg *ListPartsConfig, fn func(page *ListPartsResult) bool) error {
+	return conn.ListPartsPagesWithContext(context.Background(), listCfg, fn)
+}
+
+// ListPartsPagesWithContext calls fn with every page of parts, starting from
+// listCfg, until the listing is exhausted or fn returns false.
+func (conn *Connection) ListPartsPagesWithContext(ctx context.Context, listCfg *ListPartsConfig, fn func(page *ListPartsResult) bool) error {
+	pageCfg := *listCfg
+	for {
+		page, err := conn.ListPartsWithContext(ctx, &pageCfg)
+		if nil != err {
+			return err
+		}
+
+		if !fn(page) || !page.IsTruncated {
+			return nil
+		}
+		if err = pageCfg.nextPage(page); nil != err {
+			return err
+		}
+	}
+}
+
+// listAllParts returns every part of the upload.
+func (conn *Connection) listAllParts(ctx context.Context, listCfg *ListPartsConfig) (parts []Part, err error) {
+	err = conn.ListPartsPagesWithContext(ctx, listCfg, func(page *ListPartsResult) bool {
+		parts = append(parts, page.Parts...)
+		return true
+	})
+	return
+}
+
+// ListMultipartUploadsConfig holds the parameters of ListMultipartUploads.
+// MaxUploads of 0 leaves the server default of 1000.
+type ListMultipartUploadsConfig struct {
+	Bucket         string
+	Prefix         string
+	Delimiter      string
+	KeyMarker      string
+	UploadIdMarker string
+	MaxUploads     int
+}
+
+func (listCfg *ListMultipartUploadsConfig) args() url.Values {
+	args := url.Values{}
+	args.Add("uploads", "")
+
+	addString := func(key, value string) {
+		if "" != value {
+			args.Add(key, value)
+		}
+	}
+	addString("prefix", listCfg.Prefix)
+	addString("delimiter", listCfg.Delimiter)
+	addString("key-marker", listCfg.KeyMarker)
+	addString("upload-id-marker", listCfg.UploadIdMarker)
+	if listCfg.MaxUploads > 0 {
+		args.Add("max-uploads", strconv.Itoa(listCfg.MaxUploads))
+	}
+	return args
+}
+
+func (conn *Connection) ListMultipartUploads(listCfg *ListMultipartUploadsConfig) (result *ListMultipartUploadsResult, err error) {
+	return conn.ListMultipartUploadsWithContext(context.Background(), listCfg)
+}
+
+// ListMultipartUploadsWithContext returns one page of the uploads in progress
+// in the bucket, ordered by key and then by initiation time.
+func (conn *Connection) ListMultipartUploadsWithContext(ctx context.Context, listCfg *ListMultipartUploadsConfig) (result *ListMultipartUploadsResult, err error) {
+	_, _, body, err := conn.RequestWithContext(ctx, "GET", "/"+listCfg.Bucket, listCfg.args(), nil)
+	if nil != err {
+		return
+	}
+
+	result = &ListMultipartUploadsResult{}
+	err = xml.Unmarshal(body, result)
+	return
+}
+
+// nextPage moves listCfg past page. It fails when the truncated page gives
+// no new position to continue from, which would otherwise repeat the page
+// forever or cut the listing short.
+func (listCfg *ListMultipartUploadsConfig) nextPage(page *ListMultipartUploadsResult) error {
+	if "" == page.NextKeyMarker && "" == page.NextUploadIdMarker ||
+		page.NextKeyMarker == listCfg.KeyMarker && page.NextUploadIdMarker == listCfg.UploadIdMarker {
+		return errors.New("radosgwapi: truncated upload listing without a new marker")
+	}
+	listCfg.KeyMarker = page.NextKeyMarker
+	listCfg.UploadIdMarker = page.NextUploadIdMarker
+	return nil
+}
+
+func (conn *Connection) ListMultipartUploadsPages(listCfg *ListMultipartUploadsConfig, fn func(page *ListMultipartUploadsResult) bool) error {
+	return conn.ListMultipartUploadsPagesWithContext(context.Background(), listCfg, fn)
+}
+
+// ListMultipartUploadsPagesWithContext calls fn with every page of uploads,
+// starting from listCfg, until the listing is exhausted or fn returns false.
+func (conn *Connection) ListMultipartUploadsPagesWithContext(ctx context.Context, listCfg *ListMultipartUploadsConfig, fn func(page *ListMultipartUploadsResult) bool) error {
+	pageCfg := *listCfg
+	for {
+		page, err := conn.ListMultipartUploadsWithContext(ctx, &pageCfg)
+		if nil != err {
+			return err
+		}
+
+		if !fn(page) || !page.IsTruncated {
+			return nil
+		}
+		if err = pageCfg.nextPage(page); nil != err {
+			return err
+		}
+	}
+}
@@ -0,0 +1,155 @@
+package radosgwapi
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"time"
+)
+
+// ReapConfig holds the parameters of ReapMultipartUploads. Empty Buckets
+// scans the buckets S3 ListBuckets returns, which are only the caller's own.
+// AllBuckets instead scans every bucket in the cluster, found with the admin
+// API; it needs the "buckets=read" cap and S3 access to those buckets, as a
+// system user has. Uploads initiated less than OlderThan ago are left alone;
+// OlderThan must be positive, so that uploads in progress are never aborted
+// by default. With DryRun set, stale uploads are only reported.
+type ReapConfig struct {
+	Buckets    []string
+	AllBuckets bool
+	Prefix     string
+	OlderThan  time.Duration
+	DryRun     bool
+
+	// Now is the reference time for OlderThan, time.Now by default.
+	Now func() time.Time
+}
+
+// StaleUpload is an incomplete multipart upload found by the reaper. Err is
+// set when its parts could not be listed in full, in which case it is not
+// aborted, or when the abort failed.
+type StaleUpload struct {
+	Bucket    string
+	Key       string
+	UploadId  string
+	Initiated time.Time
+	Parts     int
+	Size      int64
+	Aborted   bool
+	Err       error
+}
+
+// ReapReport lists the stale uploads found and their part and byte totals.
+type ReapReport struct {
+	Uploads []StaleUpload
+	Parts   int
+	Size    int64
+	Aborted int
+	Failed  int
+}
+
+func (conn *Connection) ReapMultipartUploads(reapCfg *ReapConfig) (report *ReapReport, err error) {
+	return conn.ReapMultipartUploadsWithContext(context.Background(), reapCfg)
+}
+
+// ReapMultipartUploadsWithContext finds the multipart uploads older than
+// reapCfg.OlderThan, counts their parts and bytes, and aborts them unless
+// reapCfg.DryRun is set. Failures on single uploads are recorded in the
+// report and do not stop the scan; a failed listing returns the report so far
+// along with the error.
+func (conn *Connection) ReapMultipartUploadsWithContext(ctx context.Context, reapCfg *ReapConfig) (report *ReapReport, err error) {
+	if reapCfg.OlderThan <= 0 {
+		return nil, errors.New("radosgwapi: reaping multipart uploads needs a positive OlderThan")
+	}
+
+	report = &ReapReport{}
+
+	buckets := reapCfg.Buckets
+	if reapCfg.AllBuckets {
+		buckets, err = conn.ListUserBucketsWithContext(ctx, "")
+		if nil != err {
+			return
+		}
+		// The admin API names tenant buckets "tenant/bucket".
+		for i, bucket := range buckets {
+			if j := strings.Index(bucket, "/"); j >= 0 {
+				buckets[i] = TenantBucket(bucket[:j], bucket[j+1:])
+			}
+		}
+	} else if 0 == len(buckets) {
+		var result *ListAllMyBucketsResult
+		result, err = conn.ListBucketsWithContext(ctx, nil)
+		if nil != err {
+			return
+		}
+		for _, bucket := range result.Buckets {
+			buckets = append(buckets, bucket.Name)
+		}
+	}
+
+	now := time.Now
+	if nil != reapCfg.Now {
+		now = reapCfg.Now
+	}
+	cutoff := now().Add(-reapCfg.OlderThan)
+
+	for _, bucket := range buckets {
+		var stale []MultipartUpload
+		err = conn.ListMultipartUploadsPagesWithContext(ctx, &ListMultipartUploadsConfig{Bucket: bucket, Prefix: reapCfg.Prefix}, func(page *ListMultipartUploadsResult) bool {
+			for _, upload := range page.Uploads {
+				if upload.Initiated.Before(cutoff) {
+					stale = append(stale, upload)
+				}
+			}
+			return true
+		})
+		if nil != err {
+			return
+		}
+
+		for _, upload := range stale {
+			report.add(conn.reapUpload(ctx, bucket, upload, reapCfg.DryRun))
+		}
+	}
+
+	return
+}
+
+func (conn *Connection) reapUpload(ctx context.Context, bucket string, upload MultipartUpload, dryRun bool) (stale StaleUpload) {
+	stale = StaleUpload{
+		Bucket:    bucket,
+		Key:       upload.Key,
+		UploadId:  upload.UploadId,
+		Initiated: upload.Initiated,
+	}
+
+	parts, err := conn.listAllParts(ctx, &ListPartsConfig{Bucket: bucket, Key: upload.Key, UploadId: upload.UploadId})
+	if nil != err {
+		stale.Err = err
+		return
+	}
+	stale.Parts = len(parts)
+	for _, part := range parts {
+		stale.Size += part.Size
+	}
+
+	if dryRun {
+		return
+	}
+
+	stale.Err = conn.abortMultipartUpload(ctx, bucket, upload.Key, upload.UploadId)
+	stale.Aborted = nil == stale.Err
+	return
+}
+
+func (report *ReapReport) add(stale StaleUpload) {
+	report.Uploads = append(report.Uploads, stale)
+	report.Parts += stale.Parts
+	report.Size += stale.Size
+	if stale.Aborted {
+		report.Aborted++
+	}
+	if nil != stale.Err {
+		report.Failed++
+	}
+}
@@ -0,0 +1,105 @@
+package radosgwapi_test
+
+import (
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	radosgwapi "github.com/changjixiong/radosgw-api"
+)
+
+func reaperHandler(aborted *[]string) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		query := r.URL.Query()
+		switch {
+		case "/admin/bucket" == r.URL.Path:
+			w.Write([]byte(`["b1","t1/b2"]`))
+		case "/" == r.URL.Path:
+			w.Write([]byte(`<ListAllMyBucketsResult><Buckets><Bucket><Name>b1</Name></Bucket><Bucket><Name>b2</Name></Bucket></Buckets></ListAllMyBucketsResult>`))
+		case query["uploads"] != nil && "/b1" == r.URL.Path:
+			w.Write([]byte(`<ListMultipartUploadsResult>` +
+				`<Upload><Key>old</Key><UploadId>u1</UploadId><Initiated>2020-01-01T00:00:00.000Z</Initiated></Upload>` +
+				`<Upload><Key>new</Key><UploadId>u2</UploadId><Initiated>2020-01-09T00:00:00.000Z</Initiated></Upload>` +
+				`</ListMultipartUploadsResult>`))
+		case query["uploads"] != nil && "/t1:b2" == r.URL.Path:
+			w.Write([]byte(`<ListMultipartUploadsResult>` +
+				`<Upload><Key>truncated</Key><UploadId>u4</UploadId><Initiated>2020-01-01T00:00:00.000Z</Initiated></Upload>` +
+				`</ListMultipartUploadsResult>`))
+		case query["uploads"] != nil:
+			w.Write([]byte(`<ListMultipartUploadsResult>` +
+				`<Upload><Key>gone</Key><UploadId>u3</UploadId><Initiated>2020-01-01T00:00:00.000Z</Initiated></Upload>` +
+				`</ListMultipartUploadsResult>`))
+		case "GET" == r.Method && "u4" == query.Get("uploadId"):
+			w.Write([]byte(`<ListPartsResult><IsTruncated>true</IsTruncated><Part><PartNumber>1</PartNumber><Size>1</Size></Part></ListPartsResult>`))
+		case "GET" == r.Method && "u3" == query.Get("uploadId"):
+			w.WriteHeader(http.StatusNotFound)
+			w.Write([]byte(`<Error><Code>NoSuchUpload</Code></Error>`))
+		case "GET" == r.Method:
+			w.Write([]byte(`<ListPartsResult><Part><PartNumber>1</PartNumber><Size>5242880</Size></Part><Part><PartNumber>2</PartNumber><Size>100</Size></Part></ListPartsResult>`))
+		case "DELETE" == r.Method:
+			*aborted = append(*aborted, r.URL.Path+"?"+query.Get("uploadId"))
+			w.WriteHeader(http.StatusNoContent)
+		}
+	}
+}
+
+func TestReapMultipartUploads(t *testing.T) {
+	now := func() time.Time { return time.Date(2020, 1, 10, 0, 0, 0, 0, time.UTC) }
+
+	for _, dryRun := range []bool{true, false} {
+		var aborted []string
+		conn, server := newTestConnection(reaperHandler(&aborted))
+
+		report, err := conn.ReapMultipartUploads(&radosgwapi.ReapConfig{OlderThan: 7 * 24 * time.Hour, DryRun: dryRun, Now: now})
+		server.Close()
+		if nil != err {
+			t.Fatal(err)
+		}
+
+		if 2 != len(report.Uploads) || "old" != report.Uploads[0].Key || "b2" != report.Uploads[1].Bucket {
+			t.Fatalf("dry run %t: unexpected uploads %+v", dryRun, report.Uploads)
+		}
+		if 2 != report.Parts || 5242980 != report.Size || 1 != report.Failed {
+			t.Errorf("dry run %t: unexpected totals %+v", dryRun, report)
+		}
+		if dryRun && (0 != len(aborted) || 0 != report.Aborted) {
+			t.Errorf("dry run aborted %v", aborted)
+		}
+		if !dryRun && (1 != len(aborted) || !strings.HasSuffix(aborted[0], "/b1/old?u1") || 1 != report.Aborted) {
+			t.Errorf("unexpected aborts %v", aborted)
+		}
+	}
+}
+
+func TestReapMultipartUploadsBuckets(t *testing.T) {
+	var aborted []string
+	conn, server := newTestConnection(reaperHandler(&aborted))
+	defer server.Close()
+
+	report, err := conn.ReapMultipartUploads(&radosgwapi.ReapConfig{Buckets: []string{"b1"}, OlderThan: time.Hour})
+	if nil != err || 2 != len(report.Uploads) || 2 != len(aborted) {
+		t.Errorf("unexpected report %+v %v, aborted %v", report, err, aborted)
+	}
+
+	if _, err := conn.ReapMultipartUploads(&radosgwapi.ReapConfig{Buckets: []string{"b1"}}); nil == err || 2 != len(aborted) {
+		t.Errorf("expected error without OlderThan, got %v, aborted %v", err, aborted)
+	}
+}
+
+func TestReapMultipartUploadsAllBuckets(t *testing.T) {
+	var aborted []string
+	conn, server := newTestConnection(reaperHandler(&aborted))
+	defer server.Close()
+
+	report, err := conn.ReapMultipartUploads(&radosgwapi.ReapConfig{AllBuckets: true, OlderThan: time.Hour})
+	if nil != err || 3 != len(report.Uploads) || "t1:b2" != report.Uploads[2].Bucket {
+		t.Fatalf("unexpected report %+v %v", report, err)
+	}
+
+	// A part listing that cannot be completed is reported, not aborted.
+	truncated := report.Uploads[2]
+	if nil == truncated.Err || truncated.Aborted || 1 != report.Failed || 2 != len(aborted) {
+		t.Errorf("unexpected truncated upload %+v, aborted %v", truncated, aborted)
+	}
+}
@@ -0,0 +1,231 @@
+package radosgwapi
+
+import (
+	"context"
+	"crypto/md5"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// UploadCheckpoint is the state of a resumable upload, persisted as JSON
+// after every completed part.
+type UploadCheckpoint struct {
+	Bucket   string          `json:"bucket"`
+	Key      string          `json:"key"`
+	UploadId string          `json:"upload_id"`
+	Size     int64           `json:"size"`
+	PartSize int64           `json:"part_size"`
+	Parts    []CompletedPart `json:"parts"`
+}
+
+func loadCheckpoint(checkpointPath string) (checkpoint *UploadCheckpoint, err error) {
+	data, err := ioutil.ReadFile(checkpointPath)
+	if nil != err {
+		return
+	}
+
+	checkpoint = &UploadCheckpoint{}
+	err = json.Unmarshal(data, checkpoint)
+	return
+}
+
+// save writes the checkpoint to a temporary file and renames it over
+// checkpointPath, so a crash never leaves a truncated checkpoint.
+func (checkpoint *UploadCheckpoint) save(checkpointPath string) error {
+	data, err := json.Marshal(checkpoint)
+	if nil != err {
+		return err
+	}
+
+	tmp, err := ioutil.TempFile(filepath.Dir(checkpointPath), filepath.Base(checkpointPath)+".tmp")
+	if nil != err {
+		return err
+	}
+	_, err = tmp.Write(data)
+	if closeErr := tmp.Close(); nil == err {
+		err = closeErr
+	}
+	if nil != err {
+		os.Remove(tmp.Name())
+		return err
+	}
+
+	return os.Rename(tmp.Name(), checkpointPath)
+}
+
+// objectSize returns PicSize, or the size found by seeking to the end of the
+// reader.
+func objectSize(objectCfg *ObjectConfig) (int64, error) {
+	if objectCfg.PicSize > 0 {
+		return int64(objectCfg.PicSize), nil
+	}
+
+	seeker, ok := objectCfg.ObjectReader.(io.Seeker)
+	if !ok {
+		return 0, errors.New("radosgwapi: resumable upload needs PicSize or a seekable ObjectReader")
+	}
+	return seeker.Seek(0, io.SeekEnd)
+}
+
+func (uploader *MultipartUploader) UploadResumable(objectCfg *ObjectConfig, checkpointPath string) (result *CompleteMultipartUploadResult, err error) {
+	return uploader.UploadResumableWithContext(context.Background(), objectCfg, checkpointPath)
+}
+
+// UploadResumableWithContext uploads the object like UploadWithContext, but
+// records the upload in a checkpoint file at checkpointPath and does not
+// abort it on failure. Run again with the same checkpoint, it lists the parts
+// the server already has, keeps the ones whose ETag is the MD5 of the local
+// data, and uploads only the rest. ObjectReader must be
+// an io.ReaderAt such as *os.File. The checkpoint is removed on success.
+func (uploader *MultipartUploader) UploadResumableWithContext(ctx context.Context, objectCfg *ObjectConfig, checkpointPath string) (result *CompleteMultipartUploadResult, err error) {
+	source, ok := objectCfg.ObjectReader.(io.ReaderAt)
+	if !ok {
+		return nil, errors.New("radosgwapi: resumable upload needs an io.ReaderAt ObjectReader")
+	}
+	size, err := objectSize(objectCfg)
+	if nil != err {
+		return
+	}
+
+	checkpoint, err := uploader.resumeCheckpoint(ctx, objectCfg, source, size, checkpointPath)
+	if nil != err {
+		return
+	}
+
+	partCount := int((size + checkpoint.PartSize - 1) / checkpoint.PartSize)
+	if 0 == partCount {
+		partCount = 1
+	}
+	if partCount > MaxParts {
+		return nil, fmt.Errorf("radosgwapi: object needs more than %d parts of %d bytes", MaxParts, checkpoint.PartSize)
+	}
+
+	done := map[int]bool{}
+	for _, part := range checkpoint.Parts {
+		done[part.PartNumber] = true
+	}
+	missing := []int{}
+	for partNumber := 1; partNumber <= partCount; partNumber++ {
+		if !done[partNumber] {
+			missing = append(missing, partNumber)
+		}
+	}
+
+	next := func(buf []byte) (int, int, error) {
+		if 0 == len(missing) {
+			return 0, 0, nil
+		}
+		partNumber := missing[0]
+		missing = missing[1:]
+
+		offset := int64(partNumber-1) * checkpoint.PartSize
+		length := size - offset
+		if length > checkpoint.PartSize {
+			length = checkpoint.PartSize
+		}
+		n, err := source.ReadAt(buf[:length], offset)
+		if int64(n) == length {
+			err = nil
+		}
+		return partNumber, n, err
+	}
+
+	err = uploader.uploadParts(ctx, objectCfg.Bucket, objectCfg.Key, checkpoint.UploadId, checkpoint.PartSize, next, func(part CompletedPart) error {
+		checkpoint.Parts = append(checkpoint.Parts, part)
+		return checkpoint.save(checkpointPath)
+	})
+	if nil != err {
+		return
+	}
+
+	result, err = uploader.conn.CompleteMultipartUploadWithContext(ctx, objectCfg.Bucket, objectCfg.Key, checkpoint.UploadId, checkpoint.Parts)
+	if nil != err {
+		return
+	}
+
+	os.Remove(checkpointPath)
+	return
+}
+
+// resumeCheckpoint returns the checkpoint at checkpointPath with its parts
+// verified against the server, or a new upload when there is no usable
+// checkpoint.
+func (uploader *MultipartUploader) resumeCheckpoint(ctx context.Context, objectCfg *ObjectConfig, source io.ReaderAt, size int64, checkpointPath string) (checkpoint *UploadCheckpoint, err error) {
+	checkpoint, err = loadCheckpoint(checkpointPath)
+	switch {
+	case nil != err && !os.IsNotExist(err):
+		return
+	case nil != err:
+	case checkpoint.Bucket == objectCfg.Bucket && checkpoint.Key == objectCfg.Key && checkpoint.Size == size:
+		var serverParts []Part
+		serverParts, err = uploader.conn.listAllParts(ctx, &ListPartsConfig{Bucket: objectCfg.Bucket, Key: objectCfg.Key, UploadId: checkpoint.UploadId})
+		if nil == err {
+			checkpoint.Parts = verifyParts(checkpoint, serverParts, source)
+			return checkpoint, checkpoint.save(checkpointPath)
+		}
+		if !errors.Is(err, ErrNoSuchUpload) {
+			return
+		}
+	default:
+		// The checkpoint is for another object; its upload would be orphaned.
+		err = uploader.conn.abortMultipartUpload(ctx, checkpoint.Bucket, checkpoint.Key, checkpoint.UploadId)
+		if nil != err && !errors.Is(err, ErrNoSuchUpload) {
+			return
+		}
+	}
+
+	partSize, err := adjustPartSize(size, uploader.PartSize, MinPartSize)
+	if nil != err {
+		return
+	}
+
+	initHeader := objectCfg.header()
+	initHeader.Del("Content-Md5")
+
+	upload, err := uploader.conn.initiateMultipartUpload(ctx, objectCfg.Bucket, objectCfg.Key, initHeader)
+	if nil != err {
+		return
+	}
+
+	checkpoint = &UploadCheckpoint{
+		Bucket:   objectCfg.Bucket,
+		Key:      objectCfg.Key,
+		UploadId: upload.UploadId,
+		Size:     size,
+		PartSize: partSize,
+	}
+	return checkpoint, checkpoint.save(checkpointPath)
+}
+
+// verifyParts returns the server parts that have the expected size and whose
+// ETag is the MD5 of the local data. The local data is always read, as the
+// source may have changed since the checkpoint was written; parts stored with
+// a non-MD5 ETag, such as under SSE, are uploaded again.
+func verifyParts(checkpoint *UploadCheckpoint, serverParts []Part, source io.ReaderAt) []CompletedPart {
+	verified := []CompletedPart{}
+	for _, part := range serverParts {
+		offset := int64(part.PartNumber-1) * checkpoint.PartSize
+		expectedSize := checkpoint.Size - offset
+		if expectedSize > checkpoint.PartSize {
+			expectedSize = checkpoint.PartSize
+		}
+		if expectedSize < 0 || part.Size != expectedSize {
+			continue
+		}
+
+		h := md5.New()
+		if _, err := io.Copy(h, io.NewSectionReader(source, offset, expectedSize)); nil != err ||
+			hex.EncodeToString(h.Sum(nil)) != strings.Trim(part.ETag, `"`) {
+			continue
+		}
+		verified = append(verified, CompletedPart{PartNumber: part.PartNumber, ETag: part.ETag})
+	}
+	return verified
+}
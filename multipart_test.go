@@ -0,0 +1,394 @@
+package radosgwapi_test
+
+import (
+	"bytes"
+	"crypto/md5"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	radosgwapi "github.com/changjixiong/radosgw-api"
+)
+
+// multipartServer records the parts of every upload and fails the part
+// numbers in failParts.
+type multipartServer struct {
+	mu        sync.Mutex
+	parts     map[string][]byte
+	completed string
+	aborted   bool
+	failParts map[string]bool
+}
+
+// partETag is the ETag RGW returns for an unencrypted part.
+func partETag(data []byte) string {
+	return fmt.Sprintf(`"%x"`, md5.Sum(data))
+}
+
+func (s *multipartServer) handler(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	query := r.URL.Query()
+	body, _ := ioutil.ReadAll(r.Body)
+	switch {
+	case "POST" == r.Method && query["uploads"] != nil:
+		w.Write([]byte(`<InitiateMultipartUploadResult><Bucket>b</Bucket><Key>k</Key><UploadId>u1</UploadId></InitiateMultipartUploadResult>`))
+	case "PUT" == r.Method:
+		partNumber := query.Get("partNumber")
+		if s.failParts[partNumber] {
+			w.WriteHeader(http.StatusInternalServerError)
+			w.Write([]byte(`<Error><Code>InternalError</Code></Error>`))
+			return
+		}
+		s.parts[partNumber] = body
+		w.Header().Set("Etag", partETag(body))
+	case "GET" == r.Method:
+		w.Write([]byte(`<ListPartsResult><UploadId>u1</UploadId>`))
+		for partNumber := 1; partNumber <= len(s.parts); partNumber++ {
+			if part, ok := s.parts[fmt.Sprint(partNumber)]; ok {
+				fmt.Fprintf(w, `<Part><PartNumber>%d</PartNumber><ETag>%s</ETag><Size>%d</Size></Part>`, partNumber, partETag(part), len(part))
+			}
+		}
+		w.Write([]byte(`</ListPartsResult>`))
+	case "POST" == r.Method:
+		s.completed = string(body)
+		w.Write([]byte(`<CompleteMultipartUploadResult><Location>http://h/b/k</Location><Bucket>b</Bucket><Key>k</Key><ETag>"final"</ETag></CompleteMultipartUploadResult>`))
+	case "DELETE" == r.Method:
+		s.aborted = true
+		w.WriteHeader(http.StatusNoContent)
+	}
+}
+
+func TestMultipartUploader(t *testing.T) {
+	cases := []struct {
+		size      int
+		partSizes []int
+	}{
+		{12 << 20, []int{5 << 20, 5 << 20, 2 << 20}},
+		{10 << 20, []int{5 << 20, 5 << 20}},
+		{0, []int{0}},
+	}
+
+	for _, tc := range cases {
+		s := &multipartServer{parts: map[string][]byte{}}
+		conn, server := newTestConnection(s.handler)
+
+		data := bytes.Repeat([]byte{'x'}, tc.size)
+		result, err := conn.NewMultipartUploader(5 << 20).Upload(&radosgwapi.ObjectConfig{Bucket: "b", Key: "k", ObjectReader: bytes.NewReader(data)})
+		server.Close()
+
+		if nil != err || `"final"` != result.ETag || "b" != result.Bucket {
+			t.Fatalf("size %d: unexpected result %+v %v", tc.size, result, err)
+		}
+		if len(tc.partSizes) != len(s.parts) {
+			t.Errorf("size %d: uploaded %d parts", tc.size, len(s.parts))
+		}
+		for i, partSize := range tc.partSizes {
+			if partSize != len(s.parts[fmt.Sprint(i+1)]) {
+				t.Errorf("size %d: part %d has %d bytes", tc.size, i+1, len(s.parts[fmt.Sprint(i+1)]))
+			}
+		}
+		if strings.Count(s.completed, "<Part>") != len(tc.partSizes) || s.aborted {
+			t.Errorf("size %d: unexpected completion %s, aborted %t", tc.size, s.completed, s.aborted)
+		}
+	}
+}
+
+func TestMultipartUploaderAbort(t *testing.T) {
+	s := &multipartServer{parts: map[string][]byte{}, failParts: map[string]bool{"2": true}}
+	conn, server := newTestConnection(s.handler)
+	defer server.Close()
+
+	data := bytes.Repeat([]byte{'x'}, 12<<20)
+	_, err := conn.NewMultipartUploader(0).Upload(&radosgwapi.ObjectConfig{Bucket: "b", Key: "k", ObjectReader: bytes.NewReader(data)})
+
+	var rgwErr *radosgwapi.Error
+	if !errors.As(err, &rgwErr) || "InternalError" != rgwErr.Code {
+		t.Errorf("expected InternalError, got %v", err)
+	}
+	if !s.aborted || "" != s.completed {
+		t.Errorf("expected abort, aborted %t completed %q", s.aborted, s.completed)
+	}
+}
+
+func TestMultipartUploaderConcurrency(t *testing.T) {
+	s := &multipartServer{parts: map[string][]byte{}}
+	var inFlight, maxInFlight int32
+	conn, server := newTestConnection(func(w http.ResponseWriter, r *http.Request) {
+		if "PUT" == r.Method {
+			n := atomic.AddInt32(&inFlight, 1)
+			for {
+				max := atomic.LoadInt32(&maxInFlight)
+				if n <= max || atomic.CompareAndSwapInt32(&maxInFlight, max, n) {
+					break
+				}
+			}
+			time.Sleep(50 * time.Millisecond)
+			defer atomic.AddInt32(&inFlight, -1)
+		}
+		s.handler(w, r)
+	})
+	defer server.Close()
+
+	data := make([]byte, 28<<20)
+	for i := range data {
+		data[i] = byte(i / (5 << 20))
+	}
+
+	uploader := conn.NewMultipartUploader(5 << 20)
+	uploader.Concurrency = 3
+	_, err := uploader.Upload(&radosgwapi.ObjectConfig{Bucket: "b", Key: "k", ObjectReader: bytes.NewReader(data)})
+	if nil != err {
+		t.Fatal(err)
+	}
+
+	if 3 != maxInFlight {
+		t.Errorf("expected 3 parts in flight, got %d", maxInFlight)
+	}
+	for i := 1; i <= 6; i++ {
+		part := s.parts[fmt.Sprint(i)]
+		if 0 == len(part) || byte(i-1) != part[0] {
+			t.Errorf("part %d has wrong content", i)
+		}
+	}
+	expected := ""
+	for i := 1; i <= 6; i++ {
+		expected += fmt.Sprintf(`<Part><PartNumber>%d</PartNumber><ETag>&#34;%s&#34;</ETag></Part>`, i, strings.Trim(partETag(s.parts[fmt.Sprint(i)]), `"`))
+	}
+	if !strings.Contains(s.completed, expected) {
+		t.Errorf("parts out of order: %s", s.completed)
+	}
+}
+
+func TestMultipartUploaderResumable(t *testing.T) {
+	s := &multipartServer{parts: map[string][]byte{}, failParts: map[string]bool{"3": true}}
+	var puts []string
+	conn, server := newTestConnection(func(w http.ResponseWriter, r *http.Request) {
+		if "PUT" == r.Method {
+			puts = append(puts, r.URL.Query().Get("partNumber"))
+		}
+		s.handler(w, r)
+	})
+	defer server.Close()
+
+	dir, err := ioutil.TempDir("", "radosgwapi")
+	if nil != err {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	checkpointPath := filepath.Join(dir, "upload.json")
+
+	data := bytes.Repeat([]byte{'x'}, 12<<20)
+	objectCfg := &radosgwapi.ObjectConfig{Bucket: "b", Key: "k", ObjectReader: bytes.NewReader(data)}
+
+	_, err = conn.NewMultipartUploader(5<<20).UploadResumable(objectCfg, checkpointPath)
+	if nil == err || s.aborted {
+		t.Fatalf("expected failure without abort, got %v, aborted %t", err, s.aborted)
+	}
+	checkpoint, err := ioutil.ReadFile(checkpointPath)
+	if nil != err || !strings.Contains(string(checkpoint), `"upload_id":"u1"`) {
+		t.Fatalf("unexpected checkpoint %s %v", checkpoint, err)
+	}
+
+	s.failParts = nil
+	puts = nil
+	result, err := conn.NewMultipartUploader(5<<20).UploadResumable(objectCfg, checkpointPath)
+	if nil != err || `"final"` != result.ETag {
+		t.Fatalf("unexpected result %+v %v", result, err)
+	}
+	if 1 != len(puts) || "3" != puts[0] {
+		t.Errorf("expected only part 3 to be uploaded, got %v", puts)
+	}
+	if 3 != strings.Count(s.completed, "<Part>") {
+		t.Errorf("unexpected completion %s", s.completed)
+	}
+	if _, err := os.Stat(checkpointPath); !os.IsNotExist(err) {
+		t.Errorf("expected checkpoint to be removed, got %v", err)
+	}
+}
+
+func TestMultipartPrimitives(t *testing.T) {
+	s := &multipartServer{parts: map[string][]byte{}}
+	conn, server := newTestConnection(s.handler)
+	defer server.Close()
+
+	upload, err := conn.InitiateMultipartUpload("b", "k", &radosgwapi.PutObjectOptions{ContentType: "text/plain"})
+	if nil != err || "u1" != upload.UploadId {
+		t.Fatalf("unexpected upload %+v %v", upload, err)
+	}
+
+	parts := []radosgwapi.CompletedPart{}
+	for _, partNumber := range []int{2, 1} {
+		part, err := conn.UploadPart(&radosgwapi.UploadPartConfig{Bucket: "b", Key: "k", UploadId: upload.UploadId, PartNumber: partNumber, Data: []byte("data")})
+		if nil != err {
+			t.Fatal(err)
+		}
+		parts = append(parts, *part)
+	}
+
+	listed, err := conn.ListParts(&radosgwapi.ListPartsConfig{Bucket: "b", Key: "k", UploadId: upload.UploadId})
+	if nil != err || 2 != len(listed.Parts) || partETag([]byte("data")) != listed.Parts[0].ETag || 4 != listed.Parts[0].Size {
+		t.Fatalf("unexpected parts %+v %v", listed, err)
+	}
+
+	_, err = conn.CompleteMultipartUpload("b", "k", upload.UploadId, parts)
+	if nil != err || strings.Index(s.completed, "<PartNumber>1<") > strings.Index(s.completed, "<PartNumber>2<") {
+		t.Errorf("expected sorted parts, got %s %v", s.completed, err)
+	}
+
+	if err := conn.AbortMultipartUpload("b", "k", upload.UploadId); nil != err || !s.aborted {
+		t.Errorf("expected abort, got %v", err)
+	}
+
+	if _, err := conn.UploadPart(&radosgwapi.UploadPartConfig{Bucket: "b", Key: "k", UploadId: "u1"}); nil == err {
+		t.Errorf("expected part number error")
+	}
+}
+
+func TestListMultipartUploadsPages(t *testing.T) {
+	var queries []string
+	conn, server := newTestConnection(func(w http.ResponseWriter, r *http.Request) {
+		queries = append(queries, r.URL.RawQuery)
+		if "" == r.URL.Query().Get("key-marker") {
+			w.Write([]byte(`<ListMultipartUploadsResult><IsTruncated>true</IsTruncated><NextKeyMarker>a</NextKeyMarker><NextUploadIdMarker>u1</NextUploadIdMarker>` +
+				`<Upload><Key>a</Key><UploadId>u1</UploadId><Initiated>2020-01-02T03:04:05.000Z</Initiated></Upload></ListMultipartUploadsResult>`))
+			return
+		}
+		w.Write([]byte(`<ListMultipartUploadsResult><IsTruncated>false</IsTruncated><Upload><Key>b</Key><UploadId>u2</UploadId></Upload>` +
+			`<CommonPrefixes><Prefix>dir/</Prefix></CommonPrefixes></ListMultipartUploadsResult>`))
+	})
+	defer server.Close()
+
+	uploads := []radosgwapi.MultipartUpload{}
+	err := conn.ListMultipartUploadsPages(&radosgwapi.ListMultipartUploadsConfig{Bucket: "b", Prefix: "p", Delimiter: "/"}, func(page *radosgwapi.ListMultipartUploadsResult) bool {
+		uploads = append(uploads, page.Uploads...)
+		return true
+	})
+	if nil != err || 2 != len(uploads) || "u2" != uploads[1].UploadId || 2020 != uploads[0].Initiated.Year() {
+		t.Fatalf("unexpected uploads %+v %v", uploads, err)
+	}
+	if 2 != len(queries) || !strings.Contains(queries[0], "delimiter=%2F") || !strings.Contains(queries[1], "upload-id-marker=u1") {
+		t.Errorf("unexpected queries %v", queries)
+	}
+}
+
+func TestPutObjectByPicResponse(t *testing.T) {
+	s := &multipartServer{parts: map[string][]byte{}}
+	conn, server := newTestConnection(s.handler)
+	defer server.Close()
+
+	body, statusCode, err := conn.PutObjectByPic(&radosgwapi.ObjectConfig{Bucket: "b", Key: "k", ObjectReader: strings.NewReader("data")})
+	expected := `<CompleteMultipartUploadResult><Location>http://h/b/k</Location><Bucket>b</Bucket><Key>k</Key><ETag>"final"</ETag></CompleteMultipartUploadResult>`
+	if nil != err || http.StatusOK != statusCode || expected != string(body) {
+		t.Errorf("unexpected response %d %s %v", statusCode, body, err)
+	}
+
+	s.failParts = map[string]bool{"1": true}
+	body, statusCode, err = conn.PutObjectByPic(&radosgwapi.ObjectConfig{Bucket: "b", Key: "k", ObjectReader: strings.NewReader("data")})
+	if nil == err || http.StatusInternalServerError != statusCode || `<Error><Code>InternalError</Code></Error>` != string(body) {
+		t.Errorf("unexpected failure %d %s %v", statusCode, body, err)
+	}
+}
+
+func TestMultipartListingWithoutProgress(t *testing.T) {
+	pages := map[string]string{
+		"repeated": `<ListMultipartUploadsResult><IsTruncated>true</IsTruncated><NextKeyMarker>a</NextKeyMarker><NextUploadIdMarker>u1</NextUploadIdMarker>` +
+			`<Upload><Key>a</Key><UploadId>u1</UploadId></Upload></ListMultipartUploadsResult>`,
+		"empty": `<ListMultipartUploadsResult><IsTruncated>true</IsTruncated><Upload><Key>a</Key><UploadId>u1</UploadId></Upload></ListMultipartUploadsResult>`,
+		"parts": `<ListPartsResult><IsTruncated>true</IsTruncated><Part><PartNumber>1</PartNumber></Part></ListPartsResult>`,
+	}
+
+	for name, page := range pages {
+		var requests int
+		conn, server := newTestConnection(func(w http.ResponseWriter, r *http.Request) {
+			requests++
+			w.Write([]byte(page))
+		})
+
+		var err error
+		if "parts" == name {
+			err = conn.ListPartsPages(&radosgwapi.ListPartsConfig{Bucket: "b", Key: "k", UploadId: "u1"}, func(page *radosgwapi.ListPartsResult) bool {
+				return true
+			})
+		} else {
+			err = conn.ListMultipartUploadsPages(&radosgwapi.ListMultipartUploadsConfig{Bucket: "b"}, func(page *radosgwapi.ListMultipartUploadsResult) bool {
+				return true
+			})
+		}
+		server.Close()
+
+		if nil == err || requests > 2 {
+			t.Errorf("%s: expected error, got %v after %d requests", name, err, requests)
+		}
+	}
+}
+
+func TestMultipartUploaderResumableChangedSource(t *testing.T) {
+	s := &multipartServer{parts: map[string][]byte{}, failParts: map[string]bool{"3": true}}
+	var puts []string
+	conn, server := newTestConnection(func(w http.ResponseWriter, r *http.Request) {
+		if "PUT" == r.Method {
+			puts = append(puts, r.URL.Query().Get("partNumber"))
+		}
+		s.handler(w, r)
+	})
+	defer server.Close()
+
+	dir, err := ioutil.TempDir("", "radosgwapi")
+	if nil != err {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	checkpointPath := filepath.Join(dir, "upload.json")
+
+	data := bytes.Repeat([]byte{'x'}, 12<<20)
+	uploader := conn.NewMultipartUploader(5 << 20)
+	if _, err := uploader.UploadResumable(&radosgwapi.ObjectConfig{Bucket: "b", Key: "k", ObjectReader: bytes.NewReader(data)}, checkpointPath); nil == err {
+		t.Fatal("expected failure")
+	}
+
+	// The source is edited in place, keeping its size.
+	s.failParts = nil
+	puts = nil
+	data[0] = 'y'
+	if _, err := uploader.UploadResumable(&radosgwapi.ObjectConfig{Bucket: "b", Key: "k", ObjectReader: bytes.NewReader(data)}, checkpointPath); nil != err {
+		t.Fatal(err)
+	}
+	if 2 != len(puts) || "y" != string(s.parts["1"][:1]) {
+		t.Errorf("expected parts 1 and 3 to be uploaded again, got %v", puts)
+	}
+}
+
+func TestMultipartUploaderResumableOtherObject(t *testing.T) {
+	s := &multipartServer{parts: map[string][]byte{}}
+	var aborted []string
+	conn, server := newTestConnection(func(w http.ResponseWriter, r *http.Request) {
+		if "DELETE" == r.Method {
+			aborted = append(aborted, r.URL.Path+"?"+r.URL.Query().Get("uploadId"))
+		}
+		s.handler(w, r)
+	})
+	defer server.Close()
+
+	dir, err := ioutil.TempDir("", "radosgwapi")
+	if nil != err {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	checkpointPath := filepath.Join(dir, "upload.json")
+	ioutil.WriteFile(checkpointPath, []byte(`{"bucket":"b","key":"old","upload_id":"u0","size":1,"part_size":5242880}`), 0644)
+
+	_, err = conn.NewMultipartUploader(0).UploadResumable(&radosgwapi.ObjectConfig{Bucket: "b", Key: "k", ObjectReader: strings.NewReader("data")}, checkpointPath)
+	if nil != err || 1 != len(aborted) || !strings.HasSuffix(aborted[0], "/b/old?u0") {
+		t.Errorf("expected the old upload to be aborted, got %v %v", aborted, err)
+	}
+}
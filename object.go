@@ -0,0 +1,222 @@
+package radosgwapi
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// GetObjectConfig holds the parameters of GetObject and HeadObject. Zero
+// values are not sent. Range is a raw Range header value, see ByteRange.
+// PartNumber selects a single part of a multipart object.
+type GetObjectConfig struct {
+	Bucket            string
+	Key               string
+	Range             string
+	IfMatch           string
+	IfNoneMatch       string
+	IfModifiedSince   time.Time
+	IfUnmodifiedSince time.Time
+	VersionId         string
+	PartNumber        int
+}
+
+func (getCfg *GetObjectConfig) args() url.Values {
+	args := url.Values{}
+	if "" != getCfg.VersionId {
+		args.Add("versionId", getCfg.VersionId)
+	}
+	if getCfg.PartNumber > 0 {
+		args.Add("partNumber", strconv.Itoa(getCfg.PartNumber))
+	}
+	return args
+}
+
+func (getCfg *GetObjectConfig) header() http.Header {
+	reqHeader := http.Header{}
+	if "" != getCfg.Range {
+		reqHeader.Set("Range", getCfg.Range)
+	}
+	if "" != getCfg.IfMatch {
+		reqHeader.Set("If-Match", getCfg.IfMatch)
+	}
+	if "" != getCfg.IfNoneMatch {
+		reqHeader.Set("If-None-Match", getCfg.IfNoneMatch)
+	}
+	if !getCfg.IfModifiedSince.IsZero() {
+		reqHeader.Set("If-Modified-Since", getCfg.IfModifiedSince.UTC().Format(http.TimeFormat))
+	}
+	if !getCfg.IfUnmodifiedSince.IsZero() {
+		reqHeader.Set("If-Unmodified-Since", getCfg.IfUnmodifiedSince.UTC().Format(http.TimeFormat))
+	}
+	return reqHeader
+}
+
+// ObjectMetadata is the object metadata carried by response headers.
+type ObjectMetadata struct {
+	ContentLength      int64
+	ContentType        string
+	ContentEncoding    string
+	ContentDisposition string
+	ContentRange       string
+	CacheControl       string
+	Expires            string
+	ETag               string
+	LastModified       time.Time
+	VersionId          string
+	StorageClass       string
+	UserMetadata       map[string]string
+
+	// PartsCount is set when the object was requested with a PartNumber.
+	PartsCount int
+
+	ObjectLockMode            string
+	ObjectLockRetainUntilDate time.Time
+	ObjectLockLegalHold       string
+}
+
+const userMetadataPrefix = "X-Amz-Meta-"
+
+// ByteRange formats the Range header for bytes start through end inclusive.
+// A negative end reads to the end of the object.
+func ByteRange(start, end int64) string {
+	if end < 0 {
+		return "bytes=" + strconv.FormatInt(start, 10) + "-"
+	}
+	return "bytes=" + strconv.FormatInt(start, 10) + "-" + strconv.FormatInt(end, 10)
+}
+
+// objectPath is the request path of key in bucket, with each key segment
+// escaped.
+func objectPath(bucket, key string) string {
+	segments := strings.Split(key, "/")
+	for i, segment := range segments {
+		segments[i] = url.PathEscape(segment)
+	}
+	return "/" + bucket + "/" + strings.Join(segments, "/")
+}
+
+func parseObjectMetadata(header http.Header) *ObjectMetadata {
+	metadata := &ObjectMetadata{
+		ContentType:        header.Get("Content-Type"),
+		ContentEncoding:    header.Get("Content-Encoding"),
+		ContentDisposition: header.Get("Content-Disposition"),
+		ContentRange:       header.Get("Content-Range"),
+		CacheControl:       header.Get("Cache-Control"),
+		Expires:            header.Get("Expires"),
+		ETag:               header.Get("Etag"),
+		VersionId:          header.Get("X-Amz-Version-Id"),
+		StorageClass:       header.Get("X-Amz-Storage-Class"),
+		UserMetadata:       map[string]string{},
+	}
+
+	metadata.ContentLength, _ = strconv.ParseInt(header.Get("Content-Length"), 10, 64)
+	metadata.LastModified, _ = http.ParseTime(header.Get("Last-Modified"))
+
+	metadata.PartsCount, _ = strconv.Atoi(header.Get("X-Amz-Mp-Parts-Count"))
+	metadata.ObjectLockMode = header.Get("X-Amz-Object-Lock-Mode")
+	metadata.ObjectLockRetainUntilDate, _ = time.Parse(time.RFC3339, header.Get("X-Amz-Object-Lock-Retain-Until-Date"))
+	metadata.ObjectLockLegalHold = header.Get("X-Amz-Object-Lock-Legal-Hold")
+
+	for key, values := range header {
+		if strings.HasPrefix(key, userMetadataPrefix) && len(values) > 0 {
+			metadata.UserMetadata[strings.ToLower(key[len(userMetadataPrefix):])] = values[0]
+		}
+	}
+
+	return metadata
+}
+
+func (conn *Connection) GetObject(getCfg *GetObjectConfig) (reader io.ReadCloser, metadata *ObjectMetadata, err error) {
+	return conn.GetObjectWithContext(context.Background(), getCfg)
+}
+
+// GetObjectWithContext streams the object. The caller must close reader.
+// A failed precondition is returned as ErrNotModified or
+// ErrPreconditionFailed. Cancelling ctx aborts the read.
+func (conn *Connection) GetObjectWithContext(ctx context.Context, getCfg *GetObjectConfig) (reader io.ReadCloser, metadata *ObjectMetadata, err error) {
+	resp, err := conn.send(ctx, "GET", objectPath(getCfg.Bucket, getCfg.Key), getCfg.args(), getCfg.header(), nil)
+	if nil != err {
+		return
+	}
+
+	err = checkResponse(resp)
+	if nil != err {
+		return
+	}
+
+	return &contextReader{ctx: ctx, body: resp.Body}, parseObjectMetadata(resp.Header), nil
+}
+
+func (conn *Connection) HeadObject(getCfg *GetObjectConfig) (metadata *ObjectMetadata, err error) {
+	return conn.HeadObjectWithContext(context.Background(), getCfg)
+}
+
+// HeadObjectWithContext returns the object metadata without the body. Since
+// a HEAD response has no error document, 404 is returned as ErrNoSuchKey and
+// 403 as ErrAccessDenied.
+func (conn *Connection) HeadObjectWithContext(ctx context.Context, getCfg *GetObjectConfig) (metadata *ObjectMetadata, err error) {
+	_, header, _, err := conn.requestWithHeader(ctx, "HEAD", objectPath(getCfg.Bucket, getCfg.Key), getCfg.args(), getCfg.header(), nil)
+	if nil != err {
+		return nil, headError(err, "NoSuchKey")
+	}
+
+	return parseObjectMetadata(header), nil
+}
+
+func (conn *Connection) HeadBucket(bucketName string) (metadata *BucketMetadata, err error) {
+	return conn.HeadBucketWithContext(context.Background(), bucketName)
+}
+
+// HeadBucketWithContext checks that the bucket exists and is accessible,
+// returning ErrNoSuchBucket or ErrAccessDenied otherwise.
+func (conn *Connection) HeadBucketWithContext(ctx context.Context, bucketName string) (metadata *BucketMetadata, err error) {
+	_, header, _, err := conn.RequestWithContext(ctx, "HEAD", "/"+bucketName, url.Values{}, nil)
+	if nil != err {
+		return nil, headError(err, "NoSuchBucket")
+	}
+
+	metadata = &BucketMetadata{Region: header.Get("X-Amz-Bucket-Region")}
+	metadata.ObjectCount, _ = strconv.ParseInt(header.Get("X-Rgw-Object-Count"), 10, 64)
+	metadata.BytesUsed, _ = strconv.ParseInt(header.Get("X-Rgw-Bytes-Used"), 10, 64)
+	return
+}
+
+// headError gives the body-less 404 and 403 of a HEAD request the code S3
+// would have put in the error document.
+func headError(err error, notFoundCode string) error {
+	e, ok := err.(*Error)
+	if !ok {
+		return err
+	}
+
+	switch e.StatusCode {
+	case http.StatusNotFound:
+		e.Code = notFoundCode
+	case http.StatusForbidden:
+		e.Code = "AccessDenied"
+	}
+	return e
+}
+
+// contextReader wraps read errors caused by ctx in an *Error.
+type contextReader struct {
+	ctx  context.Context
+	body io.ReadCloser
+}
+
+func (r *contextReader) Read(p []byte) (int, error) {
+	n, err := r.body.Read(p)
+	if nil != err && io.EOF != err {
+		err = contextError(r.ctx, err)
+	}
+	return n, err
+}
+
+func (r *contextReader) Close() error {
+	return r.body.Close()
+}
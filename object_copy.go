@@ -0,0 +1,255 @@
+package radosgwapi
+
+import (
+	"context"
+	"encoding/xml"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"time"
+)
+
+// Metadata and tagging directives of CopyObject.
+const (
+	DirectiveCopy    = "COPY"
+	DirectiveReplace = "REPLACE"
+)
+
+// CopyObjectConfig holds the parameters of CopyObject and CopyLargeObject.
+// The metadata options only apply with the REPLACE metadata directive, and
+// Tagging with the REPLACE tagging directive. The CopySourceIf fields are
+// preconditions on the source.
+type CopyObjectConfig struct {
+	SourceBucket    string
+	SourceKey       string
+	SourceVersionId string
+
+	Bucket string
+	Key    string
+
+	MetadataDirective string
+	TaggingDirective  string
+	PutObjectOptions
+
+	CopySourceIfMatch           string
+	CopySourceIfNoneMatch       string
+	CopySourceIfModifiedSince   time.Time
+	CopySourceIfUnmodifiedSince time.Time
+}
+
+// UploadPartCopyConfig holds the parameters of UploadPartCopy. SourceRange is
+// a ByteRange of the source; empty copies the whole source.
+type UploadPartCopyConfig struct {
+	Bucket     string
+	Key        string
+	UploadId   string
+	PartNumber int
+
+	SourceBucket    string
+	SourceKey       string
+	SourceVersionId string
+	SourceRange     string
+
+	CopySourceIfMatch string
+}
+
+func copySource(bucket, key, versionId string) string {
+	source := objectPath(bucket, key)
+	if "" != versionId {
+		source += "?versionId=" + url.QueryEscape(versionId)
+	}
+	return source
+}
+
+func (copyCfg *CopyObjectConfig) header() http.Header {
+	reqHeader := copyCfg.PutObjectOptions.header()
+	reqHeader.Set("X-Amz-Copy-Source", copySource(copyCfg.SourceBucket, copyCfg.SourceKey, copyCfg.SourceVersionId))
+
+	setString := func(key, value string) {
+		if "" != value {
+			reqHeader.Set(key, value)
+		}
+	}
+	setString("X-Amz-Metadata-Directive", copyCfg.MetadataDirective)
+	setString("X-Amz-Tagging-Directive", copyCfg.TaggingDirective)
+	setString("X-Amz-Copy-Source-If-Match", copyCfg.CopySourceIfMatch)
+	setString("X-Amz-Copy-Source-If-None-Match", copyCfg.CopySourceIfNoneMatch)
+	if !copyCfg.CopySourceIfModifiedSince.IsZero() {
+		reqHeader.Set("X-Amz-Copy-Source-If-Modified-Since", copyCfg.CopySourceIfModifiedSince.UTC().Format(http.TimeFormat))
+	}
+	if !copyCfg.CopySourceIfUnmodifiedSince.IsZero() {
+		reqHeader.Set("X-Amz-Copy-Source-If-Unmodified-Since", copyCfg.CopySourceIfUnmodifiedSince.UTC().Format(http.TimeFormat))
+	}
+
+	return reqHeader
+}
+
+func (conn *Connection) CopyObject(copyCfg *CopyObjectConfig) (result *CopyObjectResult, err error) {
+	return conn.CopyObjectWithContext(context.Background(), copyCfg)
+}
+
+// CopyObjectWithContext copies the object server side in a single request,
+// which S3 limits to sources of 5 GiB. Use CopyLargeObject beyond that.
+func (conn *Connection) CopyObjectWithContext(ctx context.Context, copyCfg *CopyObjectConfig) (result *CopyObjectResult, err error) {
+	statusCode, header, body, err := conn.requestWithHeader(ctx, "PUT", objectPath(copyCfg.Bucket, copyCfg.Key), url.Values{}, copyCfg.header(), nil)
+	if nil != err {
+		return
+	}
+
+	err = errorDocument(statusCode, header, body)
+	if nil != err {
+		return
+	}
+
+	result = &CopyObjectResult{}
+	err = xml.Unmarshal(body, result)
+	if nil != err {
+		return
+	}
+
+	result.VersionId = header.Get("X-Amz-Version-Id")
+	result.SourceVersionId = header.Get("X-Amz-Copy-Source-Version-Id")
+	return
+}
+
+func (conn *Connection) UploadPartCopy(partCfg *UploadPartCopyConfig) (result *CopyPartResult, err error) {
+	return conn.UploadPartCopyWithContext(context.Background(), partCfg)
+}
+
+// UploadPartCopyWithContext copies a range of the source as one part of a
+// multipart upload.
+func (conn *Connection) UploadPartCopyWithContext(ctx context.Context, partCfg *UploadPartCopyConfig) (result *CopyPartResult, err error) {
+	args := url.Values{}
+	args.Add("partNumber", strconv.Itoa(partCfg.PartNumber))
+	args.Add("uploadId", partCfg.UploadId)
+
+	reqHeader := http.Header{}
+	reqHeader.Set("X-Amz-Copy-Source", copySource(partCfg.SourceBucket, partCfg.SourceKey, partCfg.SourceVersionId))
+	if "" != partCfg.SourceRange {
+		reqHeader.Set("X-Amz-Copy-Source-Range", partCfg.SourceRange)
+	}
+	if "" != partCfg.CopySourceIfMatch {
+		reqHeader.Set("X-Amz-Copy-Source-If-Match", partCfg.CopySourceIfMatch)
+	}
+
+	statusCode, header, body, err := conn.requestWithHeader(ctx, "PUT", objectPath(partCfg.Bucket, partCfg.Key), args, reqHeader, nil)
+	if nil != err {
+		return
+	}
+
+	err = errorDocument(statusCode, header, body)
+	if nil != err {
+		return
+	}
+
+	result = &CopyPartResult{}
+	err = xml.Unmarshal(body, result)
+	return
+}
+
+func (conn *Connection) CopyLargeObject(copyCfg *CopyObjectConfig, partSize int64) (result *CompleteMultipartUploadResult, err error) {
+	return conn.CopyLargeObjectWithContext(context.Background(), copyCfg, partSize)
+}
+
+// CopyLargeObjectWithContext copies the object with a multipart upload of
+// UploadPartCopy ranges, for sources over 5 GiB. partSize is raised as
+// needed to stay within MaxParts; 0 selects MaxPartSize. Unless the
+// metadata directive is REPLACE, the source content type and user metadata
+// are carried over. The upload is aborted if any part fails.
+func (conn *Connection) CopyLargeObjectWithContext(ctx context.Context, copyCfg *CopyObjectConfig, partSize int64) (result *CompleteMultipartUploadResult, err error) {
+	source, err := conn.HeadObjectWithContext(ctx, &GetObjectConfig{
+		Bucket:            copyCfg.SourceBucket,
+		Key:               copyCfg.SourceKey,
+		VersionId:         copyCfg.SourceVersionId,
+		IfMatch:           copyCfg.CopySourceIfMatch,
+		IfNoneMatch:       copyCfg.CopySourceIfNoneMatch,
+		IfModifiedSince:   copyCfg.CopySourceIfModifiedSince,
+		IfUnmodifiedSince: copyCfg.CopySourceIfUnmodifiedSince,
+	})
+	if nil != err {
+		return
+	}
+
+	initHeader := copyCfg.header()
+	for _, key := range []string{"X-Amz-Copy-Source", "X-Amz-Metadata-Directive", "X-Amz-Tagging-Directive",
+		"X-Amz-Copy-Source-If-Match", "X-Amz-Copy-Source-If-None-Match",
+		"X-Amz-Copy-Source-If-Modified-Since", "X-Amz-Copy-Source-If-Unmodified-Since", "Content-Md5"} {
+		initHeader.Del(key)
+	}
+	if DirectiveReplace != copyCfg.MetadataDirective {
+		if "" != source.ContentType {
+			initHeader.Set("Content-Type", source.ContentType)
+		}
+		for key, value := range source.UserMetadata {
+			initHeader.Set(userMetadataPrefix+key, value)
+		}
+	}
+
+	partSize, err = adjustPartSize(source.ContentLength, partSize, MaxPartSize)
+	if nil != err {
+		return
+	}
+
+	upload, err := conn.initiateMultipartUpload(ctx, copyCfg.Bucket, copyCfg.Key, initHeader)
+	if nil != err {
+		return
+	}
+	defer func() {
+		if nil != err {
+			conn.abortMultipartUpload(context.Background(), copyCfg.Bucket, copyCfg.Key, upload.UploadId)
+		}
+	}()
+
+	parts := []CompletedPart{}
+	for offset, partNumber := int64(0), 1; offset < source.ContentLength || 1 == partNumber; offset, partNumber = offset+partSize, partNumber+1 {
+		end := offset + partSize - 1
+		if end >= source.ContentLength {
+			end = source.ContentLength - 1
+		}
+
+		partCfg := &UploadPartCopyConfig{
+			Bucket:          copyCfg.Bucket,
+			Key:             copyCfg.Key,
+			UploadId:        upload.UploadId,
+			PartNumber:      partNumber,
+			SourceBucket:    copyCfg.SourceBucket,
+			SourceKey:       copyCfg.SourceKey,
+			SourceVersionId: copyCfg.SourceVersionId,
+			// Every part must come from the object that was HEADed, or the
+			// copy would mix ranges of two objects.
+			CopySourceIfMatch: source.ETag,
+		}
+		if end >= offset {
+			partCfg.SourceRange = ByteRange(offset, end)
+		}
+
+		var part *CopyPartResult
+		part, err = conn.UploadPartCopyWithContext(ctx, partCfg)
+		if nil != err {
+			return
+		}
+		parts = append(parts, CompletedPart{PartNumber: partNumber, ETag: part.ETag})
+	}
+
+	return conn.completeMultipartUpload(ctx, copyCfg.Bucket, copyCfg.Key, upload.UploadId, parts)
+}
+
+// adjustPartSize returns partSize, or defaultSize when it is not positive,
+// raised to at least MinPartSize and to what fits size in MaxParts. It fails
+// when the result exceeds MaxPartSize.
+func adjustPartSize(size, partSize, defaultSize int64) (int64, error) {
+	if partSize <= 0 {
+		partSize = defaultSize
+	}
+	if partSize < MinPartSize {
+		partSize = MinPartSize
+	}
+	if minSize := (size + MaxParts - 1) / MaxParts; partSize < minSize {
+		partSize = minSize
+	}
+	if partSize > MaxPartSize {
+		return 0, fmt.Errorf("radosgwapi: part size %d exceeds %d", partSize, MaxPartSize)
+	}
+	return partSize, nil
+}
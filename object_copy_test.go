@@ -0,0 +1,137 @@
+package radosgwapi_test
+
+import (
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"strings"
+	"testing"
+
+	radosgwapi "github.com/changjixiong/radosgw-api"
+)
+
+func TestCopyObject(t *testing.T) {
+	var lastRequest *http.Request
+	conn, server := newTestConnection(func(w http.ResponseWriter, r *http.Request) {
+		lastRequest = r
+		if "/b/locked" == r.URL.Path {
+			w.Write([]byte(`<?xml version="1.0" encoding="UTF-8"?><Error><Code>AccessDenied</Code></Error>`))
+			return
+		}
+		w.Header().Set("X-Amz-Version-Id", "v2")
+		w.Write([]byte(`<CopyObjectResult><LastModified>2020-01-01T00:00:00.000Z</LastModified><ETag>"abc"</ETag></CopyObjectResult>`))
+	})
+	defer server.Close()
+
+	result, err := conn.CopyObject(&radosgwapi.CopyObjectConfig{
+		SourceBucket:      "src",
+		SourceKey:         "old/a b.jpg",
+		SourceVersionId:   "v1",
+		Bucket:            "b",
+		Key:               "new/a.jpg",
+		MetadataDirective: radosgwapi.DirectiveReplace,
+		PutObjectOptions: radosgwapi.PutObjectOptions{
+			ContentType:  "image/jpeg",
+			UserMetadata: map[string]string{"camera": "front"},
+			StorageClass: "STANDARD_IA",
+		},
+		CopySourceIfMatch: `"abc"`,
+	})
+	if nil != err || `"abc"` != result.ETag || "v2" != result.VersionId || 2020 != result.LastModified.Year() {
+		t.Fatalf("unexpected result %+v %v", result, err)
+	}
+	if "/src/old/a%20b.jpg?versionId=v1" != lastRequest.Header.Get("X-Amz-Copy-Source") ||
+		"REPLACE" != lastRequest.Header.Get("X-Amz-Metadata-Directive") || "image/jpeg" != lastRequest.Header.Get("Content-Type") ||
+		"front" != lastRequest.Header.Get("X-Amz-Meta-Camera") || `"abc"` != lastRequest.Header.Get("X-Amz-Copy-Source-If-Match") {
+		t.Errorf("unexpected request headers %v", lastRequest.Header)
+	}
+
+	_, err = conn.CopyObject(&radosgwapi.CopyObjectConfig{SourceBucket: "src", SourceKey: "a", Bucket: "b", Key: "locked"})
+	if !errors.Is(err, radosgwapi.ErrAccessDenied) {
+		t.Errorf("expected ErrAccessDenied from 200 error document, got %v", err)
+	}
+}
+
+func TestCopyLargeObject(t *testing.T) {
+	const size = 12 << 20
+	ranges := []string{}
+	var initHeader http.Header
+	var completeBody string
+	conn, server := newTestConnection(func(w http.ResponseWriter, r *http.Request) {
+		query := r.URL.Query()
+		switch {
+		case "HEAD" == r.Method:
+			w.Header().Set("Content-Length", fmt.Sprint(size))
+			w.Header().Set("Content-Type", "video/mp4")
+			w.Header().Set("X-Amz-Meta-Camera", "front")
+			w.Header().Set("Etag", `"src1"`)
+		case "POST" == r.Method && query["uploads"] != nil:
+			initHeader = r.Header
+			w.Write([]byte(`<InitiateMultipartUploadResult><Bucket>b</Bucket><Key>k</Key><UploadId>u1</UploadId></InitiateMultipartUploadResult>`))
+		case "PUT" == r.Method:
+			ranges = append(ranges, query.Get("partNumber")+":"+r.Header.Get("X-Amz-Copy-Source-Range"))
+			if `"src1"` != r.Header.Get("X-Amz-Copy-Source-If-Match") {
+				t.Errorf("part %s not tied to the source ETag: %v", query.Get("partNumber"), r.Header)
+			}
+			fmt.Fprintf(w, `<CopyPartResult><ETag>"etag%s"</ETag></CopyPartResult>`, query.Get("partNumber"))
+		case "POST" == r.Method:
+			body, _ := ioutil.ReadAll(r.Body)
+			completeBody = string(body)
+			w.Write([]byte(`<CompleteMultipartUploadResult><Bucket>b</Bucket><Key>k</Key><ETag>"final-3"</ETag></CompleteMultipartUploadResult>`))
+		}
+	})
+	defer server.Close()
+
+	result, err := conn.CopyLargeObject(&radosgwapi.CopyObjectConfig{SourceBucket: "src", SourceKey: "big", Bucket: "b", Key: "k"}, 5<<20)
+	if nil != err || `"final-3"` != result.ETag {
+		t.Fatalf("unexpected result %+v %v", result, err)
+	}
+	expected := []string{"1:bytes=0-5242879", "2:bytes=5242880-10485759", "3:bytes=10485760-12582911"}
+	if strings.Join(expected, ",") != strings.Join(ranges, ",") {
+		t.Errorf("unexpected ranges %v", ranges)
+	}
+	if "video/mp4" != initHeader.Get("Content-Type") || "front" != initHeader.Get("X-Amz-Meta-Camera") || "" != initHeader.Get("X-Amz-Copy-Source") {
+		t.Errorf("unexpected initiate headers %v", initHeader)
+	}
+	if !strings.Contains(completeBody, `<Part><PartNumber>3</PartNumber><ETag>&#34;etag3&#34;</ETag></Part>`) {
+		t.Errorf("unexpected complete body %s", completeBody)
+	}
+}
+
+func TestCopyLargeObjectSourceChanged(t *testing.T) {
+	var parts int
+	var aborted bool
+	conn, server := newTestConnection(func(w http.ResponseWriter, r *http.Request) {
+		switch r.Method {
+		case "HEAD":
+			w.Header().Set("Content-Length", fmt.Sprint(12<<20))
+			w.Header().Set("Etag", `"src1"`)
+		case "POST":
+			w.Write([]byte(`<InitiateMultipartUploadResult><Bucket>b</Bucket><Key>k</Key><UploadId>u1</UploadId></InitiateMultipartUploadResult>`))
+		case "PUT":
+			// The source is overwritten after the first part.
+			parts++
+			if parts > 1 {
+				w.WriteHeader(http.StatusPreconditionFailed)
+				w.Write([]byte(`<Error><Code>PreconditionFailed</Code></Error>`))
+				return
+			}
+			w.Write([]byte(`<CopyPartResult><ETag>"etag1"</ETag></CopyPartResult>`))
+		case "DELETE":
+			aborted = true
+			w.WriteHeader(http.StatusNoContent)
+		}
+	})
+	defer server.Close()
+
+	copyCfg := &radosgwapi.CopyObjectConfig{SourceBucket: "src", SourceKey: "big", Bucket: "b", Key: "k"}
+	if _, err := conn.CopyLargeObject(copyCfg, 5<<20); !errors.Is(err, radosgwapi.ErrPreconditionFailed) || !aborted {
+		t.Errorf("expected PreconditionFailed and abort, got %v, aborted %t", err, aborted)
+	}
+
+	parts = 0
+	if _, err := conn.CopyLargeObject(copyCfg, radosgwapi.MaxPartSize+1); nil == err || 0 != parts {
+		t.Errorf("expected part size error before any part, got %v after %d parts", err, parts)
+	}
+}
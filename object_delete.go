@@ -0,0 +1,133 @@
+package radosgwapi
+
+import (
+	"bytes"
+	"context"
+	"crypto/md5"
+	"encoding/base64"
+	"encoding/xml"
+	"net/http"
+	"net/url"
+)
+
+// maxDeleteObjects is the number of keys S3 accepts in one multi-object
+// delete request.
+const maxDeleteObjects = 1000
+
+// DeleteObjectConfig holds the parameters of DeleteObject. MFA is the
+// "serial token" value of the x-amz-mfa header.
+type DeleteObjectConfig struct {
+	Bucket                    string
+	Key                       string
+	VersionId                 string
+	MFA                       string
+	BypassGovernanceRetention bool
+}
+
+// DeleteObjectsConfig holds the parameters of DeleteObjects. Objects may hold
+// any number of keys; they are sent in batches of 1000. With Quiet set, only
+// the keys that failed are reported.
+type DeleteObjectsConfig struct {
+	Bucket                    string
+	Objects                   []ObjectIdentifier
+	Quiet                     bool
+	MFA                       string
+	BypassGovernanceRetention bool
+}
+
+func deleteHeader(mfa string, bypassGovernanceRetention bool) http.Header {
+	reqHeader := http.Header{}
+	if "" != mfa {
+		reqHeader.Set("X-Amz-Mfa", mfa)
+	}
+	if bypassGovernanceRetention {
+		reqHeader.Set("X-Amz-Bypass-Governance-Retention", "true")
+	}
+	return reqHeader
+}
+
+func (conn *Connection) DeleteObject(deleteCfg *DeleteObjectConfig) (result *DeleteObjectResult, err error) {
+	return conn.DeleteObjectWithContext(context.Background(), deleteCfg)
+}
+
+// DeleteObjectWithContext deletes the object, or the given version of it. In
+// a versioned bucket, deleting without VersionId creates a delete marker.
+func (conn *Connection) DeleteObjectWithContext(ctx context.Context, deleteCfg *DeleteObjectConfig) (result *DeleteObjectResult, err error) {
+	args := url.Values{}
+	if "" != deleteCfg.VersionId {
+		args.Add("versionId", deleteCfg.VersionId)
+	}
+
+	_, header, _, err := conn.requestWithHeader(ctx, "DELETE", objectPath(deleteCfg.Bucket, deleteCfg.Key), args,
+		deleteHeader(deleteCfg.MFA, deleteCfg.BypassGovernanceRetention), nil)
+	if nil != err {
+		return
+	}
+
+	return &DeleteObjectResult{
+		DeleteMarker: "true" == header.Get("X-Amz-Delete-Marker"),
+		VersionId:    header.Get("X-Amz-Version-Id"),
+	}, nil
+}
+
+func (conn *Connection) DeleteObjects(deleteCfg *DeleteObjectsConfig) (result *DeleteResult, err error) {
+	return conn.DeleteObjectsWithContext(context.Background(), deleteCfg)
+}
+
+// DeleteObjectsWithContext deletes the objects with POST /?delete, merging
+// the reports of all batches. err is only set when a whole batch fails; the
+// per-key failures are in result.Errors. On error, result holds the batches
+// that completed.
+func (conn *Connection) DeleteObjectsWithContext(ctx context.Context, deleteCfg *DeleteObjectsConfig) (result *DeleteResult, err error) {
+	result = &DeleteResult{}
+
+	for start := 0; start < len(deleteCfg.Objects); start += maxDeleteObjects {
+		end := start + maxDeleteObjects
+		if end > len(deleteCfg.Objects) {
+			end = len(deleteCfg.Objects)
+		}
+
+		var batch *DeleteResult
+		batch, err = conn.deleteObjects(ctx, deleteCfg, deleteCfg.Objects[start:end])
+		if nil != err {
+			return
+		}
+		result.Deleted = append(result.Deleted, batch.Deleted...)
+		result.Errors = append(result.Errors, batch.Errors...)
+	}
+
+	return
+}
+
+func (conn *Connection) deleteObjects(ctx context.Context, deleteCfg *DeleteObjectsConfig, objects []ObjectIdentifier) (result *DeleteResult, err error) {
+	postBody, err := xml.Marshal(&DeleteRequest{Quiet: deleteCfg.Quiet, Objects: objects})
+	if nil != err {
+		return
+	}
+
+	sum := md5.Sum(postBody)
+	reqHeader := deleteHeader(deleteCfg.MFA, deleteCfg.BypassGovernanceRetention)
+	reqHeader.Set("Content-Md5", base64.StdEncoding.EncodeToString(sum[:]))
+	reqHeader.Set("Content-Type", "application/xml")
+
+	args := url.Values{}
+	args.Add("delete", "")
+
+	_, _, body, err := conn.requestWithHeader(ctx, "POST", "/"+deleteCfg.Bucket, args, reqHeader, bytes.NewReader(postBody))
+	if nil != err {
+		return
+	}
+
+	result = &DeleteResult{}
+	err = xml.Unmarshal(body, result)
+	return
+}
+
+// Err returns the failure as an *Error, for use with errors.Is.
+func (deleteError DeleteError) Err() error {
+	return &Error{
+		Code:    deleteError.Code,
+		Message: deleteError.Message,
+		Key:     deleteError.Key,
+	}
+}
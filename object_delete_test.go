@@ -0,0 +1,87 @@
+package radosgwapi_test
+
+import (
+	"crypto/md5"
+	"encoding/base64"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"testing"
+
+	radosgwapi "github.com/changjixiong/radosgw-api"
+)
+
+func TestDeleteObject(t *testing.T) {
+	var lastRequest *http.Request
+	conn, server := newTestConnection(func(w http.ResponseWriter, r *http.Request) {
+		lastRequest = r
+		w.Header().Set("X-Amz-Delete-Marker", "true")
+		w.Header().Set("X-Amz-Version-Id", "dm1")
+		w.WriteHeader(http.StatusNoContent)
+	})
+	defer server.Close()
+
+	result, err := conn.DeleteObject(&radosgwapi.DeleteObjectConfig{
+		Bucket:                    "b",
+		Key:                       "thumbs/1.jpg",
+		VersionId:                 "v1",
+		MFA:                       "serial 123456",
+		BypassGovernanceRetention: true,
+	})
+	if nil != err || !result.DeleteMarker || "dm1" != result.VersionId {
+		t.Fatalf("unexpected result %+v %v", result, err)
+	}
+	if "DELETE" != lastRequest.Method || "/b/thumbs/1.jpg" != lastRequest.URL.Path || "v1" != lastRequest.URL.Query().Get("versionId") ||
+		"serial 123456" != lastRequest.Header.Get("X-Amz-Mfa") || "true" != lastRequest.Header.Get("X-Amz-Bypass-Governance-Retention") {
+		t.Errorf("unexpected request %s %s %v", lastRequest.Method, lastRequest.URL, lastRequest.Header)
+	}
+}
+
+func TestDeleteObjects(t *testing.T) {
+	batches := []int{}
+	conn, server := newTestConnection(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := ioutil.ReadAll(r.Body)
+		sum := md5.Sum(body)
+		if r.URL.Query()["delete"] == nil || base64.StdEncoding.EncodeToString(sum[:]) != r.Header.Get("Content-Md5") {
+			t.Errorf("unexpected request %s %v", r.URL, r.Header)
+		}
+
+		request := &radosgwapi.DeleteRequest{}
+		xml.Unmarshal(body, request)
+		batches = append(batches, len(request.Objects))
+
+		result := "<DeleteResult>"
+		for _, object := range request.Objects {
+			if "k0007" == object.Key {
+				result += "<Error><Key>k0007</Key><Code>AccessDenied</Code><Message>Access Denied</Message></Error>"
+			} else if !request.Quiet {
+				result += "<Deleted><Key>" + object.Key + "</Key></Deleted>"
+			}
+		}
+		w.Write([]byte(result + "</DeleteResult>"))
+	})
+	defer server.Close()
+
+	objects := []radosgwapi.ObjectIdentifier{}
+	for i := 0; i < 2500; i++ {
+		objects = append(objects, radosgwapi.ObjectIdentifier{Key: fmt.Sprintf("k%04d", i)})
+	}
+
+	result, err := conn.DeleteObjects(&radosgwapi.DeleteObjectsConfig{Bucket: "b", Objects: objects})
+	if nil != err {
+		t.Fatal(err)
+	}
+	if fmt.Sprint([]int{1000, 1000, 500}) != fmt.Sprint(batches) || 2499 != len(result.Deleted) || 1 != len(result.Errors) {
+		t.Errorf("unexpected batches %v, %d deleted, %d errors", batches, len(result.Deleted), len(result.Errors))
+	}
+	if !errors.Is(result.Errors[0].Err(), radosgwapi.ErrAccessDenied) {
+		t.Errorf("unexpected error %v", result.Errors[0].Err())
+	}
+
+	result, err = conn.DeleteObjects(&radosgwapi.DeleteObjectsConfig{Bucket: "b", Objects: objects[:10], Quiet: true})
+	if nil != err || 0 != len(result.Deleted) || 1 != len(result.Errors) {
+		t.Errorf("unexpected quiet result %+v %v", result, err)
+	}
+}
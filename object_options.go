@@ -0,0 +1,60 @@
+package radosgwapi
+
+import (
+	"net/http"
+	"time"
+)
+
+// Canned ACLs.
+const (
+	ACLPrivate                = "private"
+	ACLPublicRead             = "public-read"
+	ACLPublicReadWrite        = "public-read-write"
+	ACLAuthenticatedRead      = "authenticated-read"
+	ACLBucketOwnerRead        = "bucket-owner-read"
+	ACLBucketOwnerFullControl = "bucket-owner-full-control"
+)
+
+// PutObjectOptions are the headers stored with an object on upload. Zero
+// values are not sent. Tagging is in URL query form, such as the Encode of a
+// url.Values. ContentMD5 is the base64 MD5 of the whole body and only applies
+// to single-request uploads; multipart uploads send the MD5 of every part
+// instead.
+type PutObjectOptions struct {
+	ContentType        string
+	ContentEncoding    string
+	ContentDisposition string
+	CacheControl       string
+	Expires            time.Time
+	UserMetadata       map[string]string
+	StorageClass       string
+	ACL                string
+	Tagging            string
+	ContentMD5         string
+}
+
+func (opts *PutObjectOptions) header() http.Header {
+	reqHeader := http.Header{}
+
+	setString := func(key, value string) {
+		if "" != value {
+			reqHeader.Set(key, value)
+		}
+	}
+	setString("Content-Type", opts.ContentType)
+	setString("Content-Encoding", opts.ContentEncoding)
+	setString("Content-Disposition", opts.ContentDisposition)
+	setString("Cache-Control", opts.CacheControl)
+	setString("X-Amz-Storage-Class", opts.StorageClass)
+	setString("X-Amz-Acl", opts.ACL)
+	setString("X-Amz-Tagging", opts.Tagging)
+	setString("Content-Md5", opts.ContentMD5)
+	if !opts.Expires.IsZero() {
+		reqHeader.Set("Expires", opts.Expires.UTC().Format(http.TimeFormat))
+	}
+	for key, value := range opts.UserMetadata {
+		reqHeader.Set(userMetadataPrefix+key, value)
+	}
+
+	return reqHeader
+}
@@ -0,0 +1,165 @@
+package radosgwapi_test
+
+import (
+	"context"
+	"errors"
+	"io/ioutil"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	radosgwapi "github.com/changjixiong/radosgw-api"
+)
+
+func TestGetObject(t *testing.T) {
+	var lastRequest *http.Request
+	conn, server := newTestConnection(func(w http.ResponseWriter, r *http.Request) {
+		lastRequest = r
+		if `"abc"` == r.Header.Get("If-None-Match") {
+			w.WriteHeader(http.StatusNotModified)
+			return
+		}
+		w.Header().Set("Content-Type", "video/mp4")
+		w.Header().Set("Etag", `"abc"`)
+		w.Header().Set("Last-Modified", "Wed, 01 Jan 2020 00:00:00 GMT")
+		w.Header().Set("X-Amz-Meta-Camera", "front")
+		w.Header().Set("X-Amz-Version-Id", "v1")
+		w.Header().Set("Content-Range", "bytes 0-4/100")
+		w.WriteHeader(http.StatusPartialContent)
+		w.Write([]byte("hello"))
+	})
+	defer server.Close()
+
+	reader, metadata, err := conn.GetObject(&radosgwapi.GetObjectConfig{
+		Bucket:          "b",
+		Key:             "videos/a b.mp4",
+		Range:           radosgwapi.ByteRange(0, 4),
+		IfModifiedSince: time.Date(2019, 1, 1, 0, 0, 0, 0, time.UTC),
+		VersionId:       "v1",
+	})
+	if nil != err {
+		t.Fatal(err)
+	}
+	content, _ := ioutil.ReadAll(reader)
+	reader.Close()
+
+	if "hello" != string(content) || 5 != metadata.ContentLength || "video/mp4" != metadata.ContentType ||
+		`"abc"` != metadata.ETag || 2020 != metadata.LastModified.Year() || "front" != metadata.UserMetadata["camera"] ||
+		"v1" != metadata.VersionId || "bytes 0-4/100" != metadata.ContentRange {
+		t.Errorf("unexpected object %q %+v", content, metadata)
+	}
+	if "/b/videos/a b.mp4" != lastRequest.URL.Path || "bytes=0-4" != lastRequest.Header.Get("Range") ||
+		"Tue, 01 Jan 2019 00:00:00 GMT" != lastRequest.Header.Get("If-Modified-Since") || "v1" != lastRequest.URL.Query().Get("versionId") {
+		t.Errorf("unexpected request %s %v", lastRequest.URL, lastRequest.Header)
+	}
+
+	_, _, err = conn.GetObjectWithContext(context.Background(), &radosgwapi.GetObjectConfig{Bucket: "b", Key: "k", IfNoneMatch: `"abc"`})
+	if !errors.Is(err, radosgwapi.ErrNotModified) {
+		t.Errorf("expected ErrNotModified, got %v", err)
+	}
+}
+
+func TestHeadObjectAndBucket(t *testing.T) {
+	conn, server := newTestConnection(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/b/k":
+			w.Header().Set("Content-Length", "1048576")
+			w.Header().Set("Etag", `"abc-2"`)
+			w.Header().Set("X-Amz-Storage-Class", "STANDARD_IA")
+			w.Header().Set("X-Amz-Mp-Parts-Count", "2")
+			w.Header().Set("X-Amz-Object-Lock-Mode", "GOVERNANCE")
+			w.Header().Set("X-Amz-Object-Lock-Retain-Until-Date", "2030-01-01T00:00:00Z")
+			w.Header().Set("X-Amz-Meta-Owner", "alice")
+		case "/b":
+			w.Header().Set("X-RGW-Object-Count", "3")
+			w.Header().Set("X-RGW-Bytes-Used", "300")
+		case "/private", "/b/private":
+			w.WriteHeader(http.StatusForbidden)
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	})
+	defer server.Close()
+
+	metadata, err := conn.HeadObject(&radosgwapi.GetObjectConfig{Bucket: "b", Key: "k", PartNumber: 1})
+	if nil != err {
+		t.Fatal(err)
+	}
+	if 1048576 != metadata.ContentLength || "STANDARD_IA" != metadata.StorageClass || 2 != metadata.PartsCount ||
+		"GOVERNANCE" != metadata.ObjectLockMode || 2030 != metadata.ObjectLockRetainUntilDate.Year() ||
+		"alice" != metadata.UserMetadata["owner"] {
+		t.Errorf("unexpected metadata %+v", metadata)
+	}
+
+	if _, err = conn.HeadObject(&radosgwapi.GetObjectConfig{Bucket: "b", Key: "missing"}); !errors.Is(err, radosgwapi.ErrNoSuchKey) {
+		t.Errorf("expected ErrNoSuchKey, got %v", err)
+	}
+	if _, err = conn.HeadObject(&radosgwapi.GetObjectConfig{Bucket: "b", Key: "private"}); !errors.Is(err, radosgwapi.ErrAccessDenied) {
+		t.Errorf("expected ErrAccessDenied, got %v", err)
+	}
+
+	bucketMetadata, err := conn.HeadBucket("b")
+	if nil != err || 3 != bucketMetadata.ObjectCount || 300 != bucketMetadata.BytesUsed {
+		t.Errorf("unexpected bucket metadata %+v %v", bucketMetadata, err)
+	}
+	if _, err = conn.HeadBucket("missing"); !errors.Is(err, radosgwapi.ErrNoSuchBucket) {
+		t.Errorf("expected ErrNoSuchBucket, got %v", err)
+	}
+	if _, err = conn.HeadBucket("private"); !errors.Is(err, radosgwapi.ErrAccessDenied) {
+		t.Errorf("expected ErrAccessDenied, got %v", err)
+	}
+}
+
+func TestPutObjectOptions(t *testing.T) {
+	requests := []*http.Request{}
+	conn, server := newTestConnection(func(w http.ResponseWriter, r *http.Request) {
+		ioutil.ReadAll(r.Body)
+		requests = append(requests, r)
+		switch {
+		case r.URL.Query()["uploads"] != nil:
+			w.Write([]byte(`<InitiateMultipartUploadResult><Bucket>b</Bucket><Key>k</Key><UploadId>u1</UploadId></InitiateMultipartUploadResult>`))
+		case "PUT" == r.Method:
+			w.Header().Set("Etag", `"etag"`)
+		case "POST" == r.Method:
+			w.Write([]byte(`<CompleteMultipartUploadResult><ETag>"final"</ETag></CompleteMultipartUploadResult>`))
+		}
+	})
+	defer server.Close()
+
+	options := radosgwapi.PutObjectOptions{
+		ContentType:        "image/png",
+		ContentDisposition: "attachment",
+		CacheControl:       "max-age=60",
+		Expires:            time.Date(2030, 1, 1, 0, 0, 0, 0, time.UTC),
+		UserMetadata:       map[string]string{"camera": "front"},
+		StorageClass:       "STANDARD_IA",
+		ACL:                radosgwapi.ACLPublicRead,
+		Tagging:            "team=pics",
+		ContentMD5:         "XUFAKrxLKna5cZ2REBfFkg==",
+	}
+
+	_, _, err := conn.PutObject(&radosgwapi.ObjectConfig{Bucket: "b", Key: "k", ObjectReader: strings.NewReader("hello"), PutObjectOptions: options})
+	if nil != err {
+		t.Fatal(err)
+	}
+	_, _, err = conn.PutObjectByPic(&radosgwapi.ObjectConfig{Bucket: "b", Key: "k", ObjectReader: strings.NewReader("hello"), PutObjectOptions: options})
+	if nil != err {
+		t.Fatal(err)
+	}
+
+	for i, r := range requests[:2] {
+		if "image/png" != r.Header.Get("Content-Type") || "attachment" != r.Header.Get("Content-Disposition") ||
+			"max-age=60" != r.Header.Get("Cache-Control") || "Tue, 01 Jan 2030 00:00:00 GMT" != r.Header.Get("Expires") ||
+			"front" != r.Header.Get("X-Amz-Meta-Camera") || "STANDARD_IA" != r.Header.Get("X-Amz-Storage-Class") ||
+			"public-read" != r.Header.Get("X-Amz-Acl") || "team=pics" != r.Header.Get("X-Amz-Tagging") {
+			t.Errorf("request %d: unexpected headers %v", i, r.Header)
+		}
+	}
+	if "XUFAKrxLKna5cZ2REBfFkg==" != requests[0].Header.Get("Content-Md5") || "" != requests[1].Header.Get("Content-Md5") {
+		t.Errorf("unexpected Content-MD5 %q %q", requests[0].Header.Get("Content-Md5"), requests[1].Header.Get("Content-Md5"))
+	}
+	if "XUFAKrxLKna5cZ2REBfFkg==" != requests[2].Header.Get("Content-Md5") {
+		t.Errorf("unexpected part Content-MD5 %q", requests[2].Header.Get("Content-Md5"))
+	}
+}
@@ -0,0 +1,172 @@
+package radosgwapi
+
+import (
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// PostPolicy builds the policy of a browser-based POST upload. Every setter
+// adds a condition to the policy and the matching field to the form:
+//
+//	policy := radosgwapi.NewPostPolicy("pictures", time.Now().Add(time.Hour)).
+//		SetKeyStartsWith("user1/").
+//		SetContentTypeStartsWith("image/").
+//		SetContentLengthRange(1, 10<<20)
+//	postURL, formData, err := conn.PresignPostPolicy(policy)
+//
+// The client posts formData as multipart/form-data fields to postURL, with
+// the file as the last field, named "file".
+type PostPolicy struct {
+	bucket     string
+	expiration time.Time
+	hasKey     bool
+	conditions [][]interface{}
+	formData   map[string]string
+}
+
+func NewPostPolicy(bucket string, expiration time.Time) *PostPolicy {
+	policy := &PostPolicy{
+		bucket:     bucket,
+		expiration: expiration,
+		formData:   map[string]string{},
+	}
+	policy.addEq("bucket", bucket, false)
+	return policy
+}
+
+func (policy *PostPolicy) addEq(field, value string, inForm bool) {
+	policy.conditions = append(policy.conditions, []interface{}{"eq", "$" + field, value})
+	if inForm {
+		policy.formData[field] = value
+	}
+}
+
+func (policy *PostPolicy) addStartsWith(field, prefix string) {
+	policy.conditions = append(policy.conditions, []interface{}{"starts-with", "$" + field, prefix})
+}
+
+// SetKey only allows the upload to key.
+func (policy *PostPolicy) SetKey(key string) *PostPolicy {
+	policy.hasKey = true
+	policy.addEq("key", key, true)
+	return policy
+}
+
+// SetKeyStartsWith allows any key under prefix. The form's key field is set
+// to prefix + "${filename}", which the client may replace.
+func (policy *PostPolicy) SetKeyStartsWith(prefix string) *PostPolicy {
+	policy.hasKey = true
+	policy.addStartsWith("key", prefix)
+	policy.formData["key"] = prefix + "${filename}"
+	return policy
+}
+
+func (policy *PostPolicy) SetContentType(contentType string) *PostPolicy {
+	policy.addEq("Content-Type", contentType, true)
+	return policy
+}
+
+// SetContentTypeStartsWith allows any content type under prefix, such as
+// "image/". The client must add the Content-Type field itself.
+func (policy *PostPolicy) SetContentTypeStartsWith(prefix string) *PostPolicy {
+	policy.addStartsWith("Content-Type", prefix)
+	return policy
+}
+
+// SetContentLengthRange limits the size of the uploaded file, in bytes.
+func (policy *PostPolicy) SetContentLengthRange(min, max int64) *PostPolicy {
+	policy.conditions = append(policy.conditions, []interface{}{"content-length-range", min, max})
+	return policy
+}
+
+// SetSuccessActionStatus sets the status returned on success, 200, 201 or
+// 204. 201 returns an XML document describing the object.
+func (policy *PostPolicy) SetSuccessActionStatus(status int) *PostPolicy {
+	policy.addEq("success_action_status", strconv.Itoa(status), true)
+	return policy
+}
+
+func (policy *PostPolicy) SetSuccessActionRedirect(redirect string) *PostPolicy {
+	policy.addEq("success_action_redirect", redirect, true)
+	return policy
+}
+
+func (policy *PostPolicy) SetACL(acl string) *PostPolicy {
+	policy.addEq("acl", acl, true)
+	return policy
+}
+
+// SetUserMetadata stores key as x-amz-meta-key with the object.
+func (policy *PostPolicy) SetUserMetadata(key, value string) *PostPolicy {
+	policy.addEq("x-amz-meta-"+strings.ToLower(key), value, true)
+	return policy
+}
+
+// PresignPostPolicy signs policy with the connection's signature version and
+// returns the URL to post to and the form fields to send, including the
+// base64 policy and its signature.
+func (conn *Connection) PresignPostPolicy(policy *PostPolicy) (postURL string, formData map[string]string, err error) {
+	return conn.presignPostPolicy(policy, time.Now().UTC())
+}
+
+func (conn *Connection) presignPostPolicy(policy *PostPolicy, now time.Time) (postURL string, formData map[string]string, err error) {
+	if "" == policy.bucket {
+		return "", nil, errors.New("radosgwapi: post policy needs a bucket")
+	}
+	if policy.expiration.IsZero() {
+		return "", nil, errors.New("radosgwapi: post policy needs an expiration")
+	}
+	if !policy.hasKey {
+		return "", nil, errors.New("radosgwapi: post policy needs a key condition")
+	}
+
+	formData = map[string]string{}
+	for field, value := range policy.formData {
+		formData[field] = value
+	}
+	conditions := append([][]interface{}{}, policy.conditions...)
+
+	var signingKey []byte
+	v4 := false
+	switch conn.Signature {
+	case SignatureV4, SignatureV4UnsignedPayload, SignatureV4Streaming:
+		v4 = true
+		region, service := conn.signingScope()
+		amzDate := now.Format(timeFormatV4)
+		signingKey = signingKeyV4(conn.SecretAccessKey, amzDate[:8], region, service)
+
+		v4Fields := [][2]string{
+			{"x-amz-algorithm", signV4Algorithm},
+			{"x-amz-credential", conn.AccessKeyID + "/" + amzDate[:8] + "/" + region + "/" + service + "/aws4_request"},
+			{"x-amz-date", amzDate},
+		}
+		for _, field := range v4Fields {
+			conditions = append(conditions, []interface{}{"eq", "$" + field[0], field[1]})
+			formData[field[0]] = field[1]
+		}
+	}
+
+	document, err := json.Marshal(map[string]interface{}{
+		"expiration": policy.expiration.UTC().Format("2006-01-02T15:04:05.000Z"),
+		"conditions": conditions,
+	})
+	if nil != err {
+		return
+	}
+
+	encodedPolicy := base64.StdEncoding.EncodeToString(document)
+	formData["policy"] = encodedPolicy
+	if v4 {
+		formData["x-amz-signature"] = hex.EncodeToString(hmacSHA256(signingKey, encodedPolicy))
+	} else {
+		formData["AWSAccessKeyId"] = conn.AccessKeyID
+		formData["signature"] = signatureV2(conn.SecretAccessKey, encodedPolicy)
+	}
+
+	return conn.Host + "/" + policy.bucket, formData, nil
+}
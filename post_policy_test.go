@@ -0,0 +1,78 @@
+package radosgwapi_test
+
+import (
+	"crypto/hmac"
+	"crypto/sha1"
+	"encoding/base64"
+	"encoding/json"
+	"net/http"
+	"testing"
+	"time"
+
+	radosgwapi "github.com/changjixiong/radosgw-api"
+)
+
+func TestPresignPostPolicy(t *testing.T) {
+	conn := radosgwapi.NewConnection("http://rgw", "access", "secret", http.Header{})
+
+	policy := radosgwapi.NewPostPolicy("pictures", time.Date(2030, 1, 2, 3, 4, 5, 0, time.UTC)).
+		SetKeyStartsWith("user1/").
+		SetContentTypeStartsWith("image/").
+		SetContentLengthRange(1, 10<<20).
+		SetSuccessActionStatus(201).
+		SetUserMetadata("Uploader", "app")
+	postURL, formData, err := conn.PresignPostPolicy(policy)
+	if nil != err {
+		t.Fatal(err)
+	}
+
+	if "http://rgw/pictures" != postURL || "user1/${filename}" != formData["key"] || "201" != formData["success_action_status"] ||
+		"app" != formData["x-amz-meta-uploader"] || "access" != formData["AWSAccessKeyId"] {
+		t.Errorf("unexpected form %s %v", postURL, formData)
+	}
+
+	mac := hmac.New(sha1.New, []byte("secret"))
+	mac.Write([]byte(formData["policy"]))
+	if base64.StdEncoding.EncodeToString(mac.Sum(nil)) != formData["signature"] {
+		t.Errorf("unexpected signature %s", formData["signature"])
+	}
+
+	document, _ := base64.StdEncoding.DecodeString(formData["policy"])
+	decoded := &struct {
+		Expiration string          `json:"expiration"`
+		Conditions [][]interface{} `json:"conditions"`
+	}{}
+	if err := json.Unmarshal(document, decoded); nil != err {
+		t.Fatal(err)
+	}
+	if "2030-01-02T03:04:05.000Z" != decoded.Expiration || 6 != len(decoded.Conditions) ||
+		"content-length-range" != decoded.Conditions[3][0] || float64(10<<20) != decoded.Conditions[3][2] {
+		t.Errorf("unexpected policy %s", document)
+	}
+}
+
+func TestPresignPostPolicyV4(t *testing.T) {
+	conn := radosgwapi.NewConnection("http://rgw", "access", "secret", http.Header{})
+	conn.SetSignature(radosgwapi.SignatureV4, "", "")
+
+	_, formData, err := conn.PresignPostPolicy(radosgwapi.NewPostPolicy("b", time.Now().Add(time.Hour)).SetKey("k"))
+	if nil != err {
+		t.Fatal(err)
+	}
+	if "AWS4-HMAC-SHA256" != formData["x-amz-algorithm"] || 64 != len(formData["x-amz-signature"]) || "k" != formData["key"] {
+		t.Errorf("unexpected form %v", formData)
+	}
+
+	document, _ := base64.StdEncoding.DecodeString(formData["policy"])
+	decoded := &struct {
+		Conditions [][]string `json:"conditions"`
+	}{}
+	json.Unmarshal(document, decoded)
+	if 5 != len(decoded.Conditions) || formData["x-amz-credential"] != decoded.Conditions[3][2] {
+		t.Errorf("unexpected policy %s", document)
+	}
+
+	if _, _, err := conn.PresignPostPolicy(radosgwapi.NewPostPolicy("b", time.Now())); nil == err {
+		t.Errorf("expected missing key error")
+	}
+}
@@ -1,6 +1,7 @@
 package radosgwapi
 
 import (
+	"context"
 	"encoding/xml"
 	"fmt"
 	"io"
@@ -9,16 +10,18 @@ import (
 	"net/url"
 	"strconv"
 	"strings"
-	"time"
-
-	awsauth "github.com/smartystreets/go-aws-auth"
 )
 
 type ObjectConfig struct {
 	Bucket       string
 	Key          string
 	ObjectReader io.Reader
-	PicSize      int
+	// PicSize is the object size when known. It sizes multipart parts, and
+	// is sent as the Content-Length of PutObject when ObjectReader cannot be
+	// sized by seeking.
+	PicSize int
+
+	PutObjectOptions
 }
 
 type Connection struct {
@@ -26,6 +29,16 @@ type Connection struct {
 	AccessKeyID     string
 	SecretAccessKey string
 	customHeader    http.Header
+
+	// Signature selects the signing scheme, SignatureV2 by default. Region
+	// and Service make up the V4 credential scope; see SetSignature.
+	Signature SignatureVersion
+	Region    string
+	Service   string
+
+	// BucketLifecycle, when set, is applied by CreateBucket to every bucket
+	// it creates.
+	BucketLifecycle *LifecycleConfiguration
 }
 
 func (conn *Connection) AddCustomHeader(key, value string) {
@@ -46,161 +59,281 @@ func NewConnection(host, accessKeyID, secretAccessKey string, customHeader http.
 	}
 }
 
-func (conn *Connection) ListBuckets(bucketName string) (body []byte, statusCode int, err error) {
+// ListBucketsConfig holds the RGW extensions of ListBuckets. With Stats set,
+// the account totals RGW reports in X-RGW-* headers are returned as well.
+// The buckets of another tenant's user are listed by the admin API's
+// ListUserBuckets, with a "tenant$user" uid.
+type ListBucketsConfig struct {
+	Stats bool
+}
+
+func (conn *Connection) ListBuckets(listCfg *ListBucketsConfig) (result *ListAllMyBucketsResult, err error) {
+	return conn.ListBucketsWithContext(context.Background(), listCfg)
+}
+
+// ListBucketsWithContext lists the buckets owned by the caller. listCfg may
+// be nil.
+func (conn *Connection) ListBucketsWithContext(ctx context.Context, listCfg *ListBucketsConfig) (result *ListAllMyBucketsResult, err error) {
+	if nil == listCfg {
+		listCfg = &ListBucketsConfig{}
+	}
+
 	args := url.Values{}
-	statusCode, _, body, err = conn.Request("GET", "/"+bucketName, args, nil)
+	if listCfg.Stats {
+		args.Add("stats", "true")
+	}
+
+	_, header, body, err := conn.RequestWithContext(ctx, "GET", "/", args, nil)
+	if nil != err {
+		return
+	}
+
+	result = &ListAllMyBucketsResult{}
+	err = xml.Unmarshal(body, result)
+	if nil != err {
+		return
+	}
+
+	if listCfg.Stats {
+		result.Stats = &AccountStats{}
+		result.Stats.ObjectCount, _ = strconv.ParseInt(header.Get("X-Rgw-Object-Count"), 10, 64)
+		result.Stats.BytesUsed, _ = strconv.ParseInt(header.Get("X-Rgw-Bytes-Used"), 10, 64)
+		result.Stats.MaxBuckets, _ = strconv.ParseInt(header.Get("X-Rgw-Quota-Max-Buckets"), 10, 64)
+	}
+
 	return
 }
 
+// TenantBucket qualifies bucketName with tenant, the form RGW expects when
+// addressing a bucket of another tenant.
+func TenantBucket(tenant, bucketName string) string {
+	if "" == tenant {
+		return bucketName
+	}
+	return tenant + ":" + bucketName
+}
+
+// SplitTenantBucket is the inverse of TenantBucket.
+func SplitTenantBucket(name string) (tenant, bucketName string) {
+	if i := strings.Index(name, ":"); i >= 0 {
+		return name[:i], name[i+1:]
+	}
+	return "", name
+}
+
 func (conn *Connection) DeleteBucket(bucketName string) (body []byte, statusCode int, err error) {
+	return conn.DeleteBucketWithContext(context.Background(), bucketName)
+}
+
+func (conn *Connection) DeleteBucketWithContext(ctx context.Context, bucketName string) (body []byte, statusCode int, err error) {
 	args := url.Values{}
-	statusCode, _, body, err = conn.Request("DELETE", "/"+bucketName, args, nil)
+	statusCode, _, body, err = conn.RequestWithContext(ctx, "DELETE", "/"+bucketName, args, nil)
 	return
 }
 
 func (conn *Connection) CreateBucket(bucketName string) (body []byte, statusCode int, err error) {
+	return conn.CreateBucketWithContext(context.Background(), bucketName)
+}
+
+// CreateBucketWithContext creates the bucket and applies conn.BucketLifecycle
+// to it. When the lifecycle cannot be applied, body, statusCode and err are
+// those of the lifecycle request; the bucket is kept.
+func (conn *Connection) CreateBucketWithContext(ctx context.Context, bucketName string) (body []byte, statusCode int, err error) {
 	args := url.Values{}
-	statusCode, _, body, err = conn.Request("PUT", "/"+bucketName, args, nil)
+	statusCode, _, body, err = conn.RequestWithContext(ctx, "PUT", "/"+bucketName, args, nil)
+	if nil != err || nil == conn.BucketLifecycle {
+		return
+	}
+
+	err = conn.PutBucketLifecycleWithContext(ctx, bucketName, conn.BucketLifecycle)
+	if rgwErr, ok := err.(*Error); ok {
+		body, statusCode = rgwErr.body, rgwErr.StatusCode
+	} else if nil != err {
+		body, statusCode = nil, 0
+	}
 	return
 }
 
 func (conn *Connection) GetBucket(bucketName string) (body []byte, statusCode int, err error) {
+	return conn.GetBucketWithContext(context.Background(), bucketName)
+}
+
+func (conn *Connection) GetBucketWithContext(ctx context.Context, bucketName string) (body []byte, statusCode int, err error) {
 
 	args := url.Values{}
 
-	statusCode, _, body, err = conn.Request("GET", "/"+bucketName, args, nil)
+	statusCode, _, body, err = conn.RequestWithContext(ctx, "GET", "/"+bucketName, args, nil)
 
 	return
 }
 
 func (conn *Connection) GetUser(uid string) (body []byte, statusCode int, err error) {
+	return conn.GetUserWithContext(context.Background(), uid)
+}
+
+func (conn *Connection) GetUserWithContext(ctx context.Context, uid string) (body []byte, statusCode int, err error) {
 
 	args := url.Values{}
 	args.Add("uid", uid)
 
-	statusCode, _, body, err = conn.Request("GET", "/admin/user", args, nil)
+	statusCode, _, body, err = conn.RequestWithContext(ctx, "GET", "/admin/user", args, nil)
 
 	return
 }
 
 func (conn *Connection) PutObject(objectCfg *ObjectConfig) (body []byte, statusCode int, err error) {
+	return conn.PutObjectWithContext(context.Background(), objectCfg)
+}
+
+func (conn *Connection) PutObjectWithContext(ctx context.Context, objectCfg *ObjectConfig) (body []byte, statusCode int, err error) {
 	args := url.Values{}
 
-	statusCode, _, body, err = conn.Request("PUT", "/"+objectCfg.Bucket+"/"+objectCfg.Key, args, objectCfg.ObjectReader)
+	reqHeader := objectCfg.header()
+	if _, ok := objectCfg.ObjectReader.(io.Seeker); !ok && objectCfg.PicSize > 0 {
+		reqHeader.Set("Content-Length", strconv.Itoa(objectCfg.PicSize))
+	}
+
+	statusCode, _, body, err = conn.requestWithHeader(ctx, "PUT", objectPath(objectCfg.Bucket, objectCfg.Key), args, reqHeader, objectCfg.ObjectReader)
 
 	return
 }
 
 func (conn *Connection) PutObjectByPic(objectCfg *ObjectConfig) (body []byte, statusCode int, err error) {
-	args := url.Values{}
-
-	statusCode, _, body, err = conn.Request("POST", "/"+objectCfg.Bucket+"/"+objectCfg.Key+"?uploads", args, nil)
-
-	if nil != err {
-		return
-	}
-
-	initiateMultipartUploadResult := &InitiateMultipartUploadResult{}
-	err = xml.Unmarshal(body, initiateMultipartUploadResult)
+	return conn.PutObjectByPicWithContext(context.Background(), objectCfg)
+}
 
-	if nil != err {
-		fmt.Println(err)
+// PutObjectByPicWithContext uploads the object with a MultipartUploader of
+// MinPartSize parts. body and statusCode are those of the complete request,
+// or of the request that failed. If the upload fails or ctx is done, the
+// multipart upload is aborted.
+func (conn *Connection) PutObjectByPicWithContext(ctx context.Context, objectCfg *ObjectConfig) (body []byte, statusCode int, err error) {
+	err = conn.NewMultipartUploader(0).upload(ctx, objectCfg, func(uploadId string, parts []CompletedPart) (err error) {
+		statusCode, _, body, err = conn.completeMultipartUploadResponse(ctx, objectCfg.Bucket, objectCfg.Key, uploadId, parts)
 		return
+	})
+	if rgwErr, ok := err.(*Error); ok && nil == body {
+		body, statusCode = rgwErr.body, rgwErr.StatusCode
 	}
+	return
+}
 
-	responseHeader := http.Header{}
-	Etags := []string{}
-
-	byte5mLen := 5 << 20
-	byteReadLen := 0
-	for partNumber := 1; ; partNumber++ {
-		byte5m := make([]byte, byte5mLen)
-		byteReadLen, err = io.ReadFull(objectCfg.ObjectReader, byte5m)
-
-		if nil == err || io.ErrUnexpectedEOF == err {
-			args.Add("partNumber", strconv.Itoa(partNumber))
-			args.Add("uploadId", initiateMultipartUploadResult.UploadId)
-			statusCode, responseHeader, _, err = conn.Request("PUT", "/"+objectCfg.Bucket+"/"+objectCfg.Key, args, strings.NewReader(string(byte5m[0:byteReadLen])))
-
-			if nil != err {
-				fmt.Println(err)
-				return
-			}
-
-			if nil != responseHeader["Etag"] {
-				Etags = append(Etags, responseHeader["Etag"]...)
-			}
+func (conn *Connection) Request(method, router string, args url.Values, io io.Reader) (statusCode int, header http.Header, body []byte, err error) {
+	return conn.RequestWithContext(context.Background(), method, router, args, io)
+}
 
-			args.Del("partNumber")
-			args.Del("uploadId")
+// RequestWithContext is Request bound to ctx. When ctx is done the request is
+// cancelled and err is an *Error wrapping ctx.Err().
+func (conn *Connection) RequestWithContext(ctx context.Context, method, router string, args url.Values, io io.Reader) (statusCode int, header http.Header, body []byte, err error) {
+	return conn.requestWithHeader(ctx, method, router, args, nil, io)
+}
 
-		} else {
-			if io.EOF == err {
-				break
-			} else {
-				fmt.Println(err)
-				return
-			}
-		}
+// requestWithHeader is RequestWithContext with extra request headers.
+func (conn *Connection) requestWithHeader(ctx context.Context, method, router string, args url.Values, reqHeader http.Header, io io.Reader) (statusCode int, header http.Header, body []byte, err error) {
 
+	resp, err := conn.send(ctx, method, router, args, reqHeader, io)
+	if err != nil {
+		return
 	}
+	defer resp.Body.Close()
 
-	args.Add("uploadId", initiateMultipartUploadResult.UploadId)
-
-	postStr := ``
-
-	for n, etag := range Etags {
-		if len(etag) > 2 {
-			postStr += fmt.Sprintf("<Part><PartNumber>%d</PartNumber><ETag>%s</ETag></Part>", n+1, etag[1:len(etag)-1])
-		}
-
+	statusCode = resp.StatusCode
+	header = resp.Header
+	body, err = ioutil.ReadAll(resp.Body)
+	if err != nil {
+		err = contextError(ctx, err)
+		return
 	}
 
-	postStr = fmt.Sprintf("<CompleteMultipartUpload>%s</CompleteMultipartUpload>", postStr)
-
-	statusCode, _, body, err = conn.Request("POST", "/"+objectCfg.Bucket+"/"+objectCfg.Key, args, strings.NewReader(postStr))
+	if statusCode < 200 || statusCode > 299 {
+		err = newError(statusCode, header, body)
+	}
 
 	return
 }
 
-func (conn *Connection) Request(method, router string, args url.Values, io io.Reader) (statusCode int, header http.Header, body []byte, err error) {
+// send signs and sends the request, leaving the response body to the caller.
+func (conn *Connection) send(ctx context.Context, method, router string, args url.Values, reqHeader http.Header, io io.Reader) (resp *http.Response, err error) {
 
 	url := fmt.Sprintf("%s%s", conn.Host, router)
 	if len(args) > 0 {
 		url += "?" + args.Encode()
 	}
-	req, err := http.NewRequest(method, url, io)
+	req, err := http.NewRequestWithContext(ctx, method, url, io)
 	if err != nil {
 		return
 	}
 
 	conn.addHttpHeader(req)
+	for key, values := range reqHeader {
+		req.Header[key] = values
+	}
 
-	awsauth.SignS3(req, awsauth.Credentials{
-		AccessKeyID:     conn.AccessKeyID,
-		SecretAccessKey: conn.SecretAccessKey,
-		Expiration:      time.Now().Add(1 * time.Minute)},
-	)
-
-	client := http.Client{}
-
-	resp, err := client.Do(req)
+	err = setContentLength(req, io)
 	if err != nil {
 		return
 	}
-	if resp.Body != nil {
-		defer resp.Body.Close()
-	}
-	statusCode = resp.StatusCode
-	header = resp.Header
-	body, err = ioutil.ReadAll(resp.Body)
+
+	err = conn.sign(req)
 	if err != nil {
 		return
 	}
 
+	client := http.Client{}
+
+	resp, err = client.Do(req)
+	if err != nil {
+		err = contextError(ctx, err)
+	}
+
 	return
 }
 
+// setContentLength sets the length of a body that http.NewRequest could not
+// size, from a Content-Length request header or by seeking to its end, so
+// that it is sent and signed with a length instead of chunked.
+func setContentLength(req *http.Request, body io.Reader) error {
+	if length := req.Header.Get("Content-Length"); "" != length {
+		req.Header.Del("Content-Length")
+		n, err := strconv.ParseInt(length, 10, 64)
+		if nil != err {
+			return err
+		}
+		req.ContentLength = n
+	} else if seeker, ok := body.(io.Seeker); ok && 0 == req.ContentLength && http.NoBody != req.Body {
+		offset, err := seeker.Seek(0, io.SeekCurrent)
+		if nil != err {
+			return err
+		}
+		end, err := seeker.Seek(0, io.SeekEnd)
+		if nil != err {
+			return err
+		}
+		if _, err = seeker.Seek(offset, io.SeekStart); nil != err {
+			return err
+		}
+		req.ContentLength = end - offset
+	} else {
+		return nil
+	}
+
+	// A known empty body is sent as such rather than chunked.
+	if 0 == req.ContentLength {
+		req.Body = http.NoBody
+	}
+	return nil
+}
+
+// checkResponse turns a non-2xx response into an *Error, closing its body.
+func checkResponse(resp *http.Response) error {
+	if resp.StatusCode >= 200 && resp.StatusCode <= 299 {
+		return nil
+	}
+
+	body, _ := ioutil.ReadAll(resp.Body)
+	resp.Body.Close()
+	return newError(resp.StatusCode, resp.Header, body)
+}
+
 func (conn *Connection) addHttpHeader(request *http.Request) {
 
 	for key, values := range conn.customHeader {
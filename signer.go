@@ -0,0 +1,397 @@
+package radosgwapi
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/sha1"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// SignatureVersion selects how a Connection signs its requests.
+type SignatureVersion int
+
+const (
+	// SignatureV2 signs with the S3 "AWS AccessKeyID:Signature" header.
+	SignatureV2 SignatureVersion = iota
+	// SignatureV4 signs the headers and the SHA256 of the payload. Bodies of
+	// unknown length are buffered in memory to compute the hash.
+	SignatureV4
+	// SignatureV4UnsignedPayload signs the headers only and sends the body
+	// as UNSIGNED-PAYLOAD.
+	SignatureV4UnsignedPayload
+	// SignatureV4Streaming sends the body as aws-chunked, signing every chunk.
+	// Its length must be known, from a sized or seekable reader or PicSize.
+	SignatureV4Streaming
+)
+
+const (
+	defaultRegion  = "us-east-1"
+	defaultService = "s3"
+
+	signV4Algorithm   = "AWS4-HMAC-SHA256"
+	timeFormatV4      = "20060102T150405Z"
+	unsignedPayload   = "UNSIGNED-PAYLOAD"
+	streamingPayload  = "STREAMING-AWS4-HMAC-SHA256-PAYLOAD"
+	streamingChunkLen = 64 << 10
+)
+
+// emptySHA256 is the hex SHA256 of an empty payload.
+var emptySHA256 = hexSHA256(nil)
+
+// signedSubresources are the query parameters included in the SigV2
+// canonical resource, in lexicographical order.
+var signedSubresources = []string{
+	"acl", "cors", "delete", "lifecycle", "location", "logging",
+	"notification", "partNumber", "policy", "requestPayment",
+	"response-cache-control", "response-content-disposition",
+	"response-content-encoding", "response-content-language",
+	"response-content-type", "response-expires", "restore", "tagging",
+	"torrent", "uploadId", "uploads", "versionId", "versioning", "versions",
+	"website",
+}
+
+// SetSignature selects the signature version used for subsequent requests.
+// region and service are only used by the V4 variants and default to
+// us-east-1 and s3; they are never derived from Host.
+func (conn *Connection) SetSignature(signature SignatureVersion, region, service string) {
+	conn.Signature = signature
+	conn.Region = region
+	conn.Service = service
+}
+
+func (conn *Connection) sign(req *http.Request) error {
+	now := time.Now().UTC()
+
+	switch conn.Signature {
+	case SignatureV4, SignatureV4UnsignedPayload, SignatureV4Streaming:
+		return conn.signV4(req, now)
+	}
+
+	conn.signV2(req, now)
+	return nil
+}
+
+func (conn *Connection) signV2(req *http.Request, now time.Time) {
+	req.Header.Set("Date", now.Format(http.TimeFormat))
+
+	stringToSign := req.Method + "\n" +
+		req.Header.Get("Content-Md5") + "\n" +
+		req.Header.Get("Content-Type") + "\n" +
+		req.Header.Get("Date") + "\n" +
+		canonicalAmzHeadersV2(req.Header) +
+		canonicalResourceV2(req.URL)
+
+	req.Header.Set("Authorization", "AWS "+conn.AccessKeyID+":"+signatureV2(conn.SecretAccessKey, stringToSign))
+}
+
+func signatureV2(secretAccessKey, stringToSign string) string {
+	mac := hmac.New(sha1.New, []byte(secretAccessKey))
+	mac.Write([]byte(stringToSign))
+	return base64.StdEncoding.EncodeToString(mac.Sum(nil))
+}
+
+func canonicalAmzHeadersV2(header http.Header) string {
+	keys := []string{}
+	values := map[string]string{}
+	for key, vals := range header {
+		lower := strings.ToLower(key)
+		if !strings.HasPrefix(lower, "x-amz-") {
+			continue
+		}
+		keys = append(keys, lower)
+		trimmed := make([]string, len(vals))
+		for i, v := range vals {
+			trimmed[i] = strings.TrimSpace(v)
+		}
+		values[lower] = strings.Join(trimmed, ",")
+	}
+	sort.Strings(keys)
+
+	str := ""
+	for _, key := range keys {
+		str += key + ":" + values[key] + "\n"
+	}
+	return str
+}
+
+func canonicalResourceV2(u *url.URL) string {
+	res := u.EscapedPath()
+	if "" == res {
+		res = "/"
+	}
+
+	query := u.Query()
+	subresources := []string{}
+	for _, name := range signedSubresources {
+		values, ok := query[name]
+		if !ok {
+			continue
+		}
+		if len(values) > 0 && "" != values[0] {
+			subresources = append(subresources, name+"="+values[0])
+		} else {
+			subresources = append(subresources, name)
+		}
+	}
+	if len(subresources) > 0 {
+		res += "?" + strings.Join(subresources, "&")
+	}
+	return res
+}
+
+func (conn *Connection) signingScope() (region, service string) {
+	region, service = conn.Region, conn.Service
+	if "" == region {
+		region = defaultRegion
+	}
+	if "" == service {
+		service = defaultService
+	}
+	return
+}
+
+func (conn *Connection) signV4(req *http.Request, now time.Time) error {
+	region, service := conn.signingScope()
+	amzDate := now.Format(timeFormatV4)
+	scope := amzDate[:8] + "/" + region + "/" + service + "/aws4_request"
+	signingKey := signingKeyV4(conn.SecretAccessKey, amzDate[:8], region, service)
+
+	req.Header.Set("X-Amz-Date", amzDate)
+
+	hasBody := nil != req.Body && http.NoBody != req.Body
+	payloadHash := emptySHA256
+	var decodedLength int64
+	switch {
+	case !hasBody:
+	case SignatureV4UnsignedPayload == conn.Signature:
+		payloadHash = unsignedPayload
+	case SignatureV4Streaming == conn.Signature:
+		length, err := requestBodyLength(req)
+		if nil != err {
+			return err
+		}
+		decodedLength = length
+		payloadHash = streamingPayload
+		contentEncoding := "aws-chunked"
+		if ce := req.Header.Get("Content-Encoding"); "" != ce {
+			contentEncoding += "," + ce
+		}
+		req.Header.Set("Content-Encoding", contentEncoding)
+		req.Header.Set("X-Amz-Decoded-Content-Length", strconv.FormatInt(decodedLength, 10))
+		req.ContentLength = streamingContentLength(decodedLength)
+	default:
+		payload, err := ioutil.ReadAll(req.Body)
+		req.Body.Close()
+		if nil != err {
+			return err
+		}
+		req.Body = ioutil.NopCloser(bytes.NewReader(payload))
+		req.ContentLength = int64(len(payload))
+		req.GetBody = nil
+		payloadHash = hexSHA256(payload)
+	}
+	req.Header.Set("X-Amz-Content-Sha256", payloadHash)
+
+	signedHeaders, canonicalHeaders := canonicalHeadersV4(req)
+	canonicalRequest := strings.Join([]string{
+		req.Method,
+		uriEncode(canonicalPath(req.URL), false),
+		canonicalQueryV4(req.URL.Query()),
+		canonicalHeaders,
+		signedHeaders,
+		payloadHash,
+	}, "\n")
+
+	stringToSign := signV4Algorithm + "\n" + amzDate + "\n" + scope + "\n" + hexSHA256([]byte(canonicalRequest))
+	signature := hex.EncodeToString(hmacSHA256(signingKey, stringToSign))
+
+	req.Header.Set("Authorization", fmt.Sprintf("%s Credential=%s/%s, SignedHeaders=%s, Signature=%s",
+		signV4Algorithm, conn.AccessKeyID, scope, signedHeaders, signature))
+
+	if SignatureV4Streaming == conn.Signature && hasBody {
+		req.Body = &chunkSigningReader{
+			body:       req.Body,
+			buf:        make([]byte, streamingChunkLen),
+			signingKey: signingKey,
+			amzDate:    amzDate,
+			scope:      scope,
+			prevSig:    signature,
+		}
+		req.GetBody = nil
+	}
+
+	return nil
+}
+
+func signingKeyV4(secretAccessKey, date, region, service string) []byte {
+	key := hmacSHA256([]byte("AWS4"+secretAccessKey), date)
+	key = hmacSHA256(key, region)
+	key = hmacSHA256(key, service)
+	return hmacSHA256(key, "aws4_request")
+}
+
+// canonicalHeadersV4 signs host and every header already set on the request,
+// except the ones a proxy or the transport may rewrite.
+func canonicalHeadersV4(req *http.Request) (signedHeaders, canonicalHeaders string) {
+	host := req.Host
+	if "" == host {
+		host = req.URL.Host
+	}
+
+	keys := []string{"host"}
+	values := map[string]string{"host": host}
+	for key, vals := range req.Header {
+		lower := strings.ToLower(key)
+		switch lower {
+		case "authorization", "user-agent", "connection", "expect", "transfer-encoding":
+			continue
+		}
+		trimmed := make([]string, len(vals))
+		for i, v := range vals {
+			trimmed[i] = strings.Join(strings.Fields(v), " ")
+		}
+		keys = append(keys, lower)
+		values[lower] = strings.Join(trimmed, ",")
+	}
+	sort.Strings(keys)
+
+	for _, key := range keys {
+		canonicalHeaders += key + ":" + values[key] + "\n"
+	}
+	return strings.Join(keys, ";"), canonicalHeaders
+}
+
+func canonicalPath(u *url.URL) string {
+	if "" == u.Path {
+		return "/"
+	}
+	return u.Path
+}
+
+func canonicalQueryV4(query url.Values) string {
+	keys := make([]string, 0, len(query))
+	for key := range query {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	pairs := []string{}
+	for _, key := range keys {
+		values := append([]string{}, query[key]...)
+		sort.Strings(values)
+		for _, v := range values {
+			pairs = append(pairs, uriEncode(key, true)+"="+uriEncode(v, true))
+		}
+	}
+	return strings.Join(pairs, "&")
+}
+
+// uriEncode percent-encodes everything but the unreserved characters, and
+// '/' too when encodeSlash is set.
+func uriEncode(s string, encodeSlash bool) string {
+	var buf bytes.Buffer
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		if 'A' <= c && c <= 'Z' || 'a' <= c && c <= 'z' || '0' <= c && c <= '9' ||
+			'-' == c || '_' == c || '.' == c || '~' == c || ('/' == c && !encodeSlash) {
+			buf.WriteByte(c)
+			continue
+		}
+		fmt.Fprintf(&buf, "%%%02X", c)
+	}
+	return buf.String()
+}
+
+// requestBodyLength returns the length of the body, which must be known:
+// aws-chunked exists to stream bodies, so they are never buffered to find it.
+func requestBodyLength(req *http.Request) (int64, error) {
+	if req.ContentLength > 0 {
+		return req.ContentLength, nil
+	}
+	return 0, errors.New("radosgwapi: SignatureV4Streaming needs the body length, from a sized or seekable reader or a Content-Length header")
+}
+
+func streamingChunkSize(dataLen int64) int64 {
+	return int64(len(strconv.FormatInt(dataLen, 16))+len(";chunk-signature=")+64+2) + dataLen + 2
+}
+
+// streamingContentLength is the encoded length of an aws-chunked body.
+func streamingContentLength(decodedLength int64) int64 {
+	length := (decodedLength / streamingChunkLen) * streamingChunkSize(streamingChunkLen)
+	if rem := decodedLength % streamingChunkLen; rem > 0 {
+		length += streamingChunkSize(rem)
+	}
+	return length + streamingChunkSize(0)
+}
+
+// chunkSigningReader encodes body as aws-chunked, chaining each chunk
+// signature from the previous one, starting with the request signature.
+type chunkSigningReader struct {
+	body       io.ReadCloser
+	buf        []byte
+	out        bytes.Buffer
+	signingKey []byte
+	amzDate    string
+	scope      string
+	prevSig    string
+	done       bool
+}
+
+func (r *chunkSigningReader) Read(p []byte) (int, error) {
+	if 0 == r.out.Len() && !r.done {
+		n, err := io.ReadFull(r.body, r.buf)
+		if n > 0 {
+			r.writeChunk(r.buf[:n])
+		}
+		switch err {
+		case nil:
+		case io.EOF, io.ErrUnexpectedEOF:
+			r.writeChunk(nil)
+			r.done = true
+		default:
+			return 0, err
+		}
+	}
+
+	if 0 == r.out.Len() {
+		return 0, io.EOF
+	}
+	return r.out.Read(p)
+}
+
+func (r *chunkSigningReader) writeChunk(data []byte) {
+	stringToSign := "AWS4-HMAC-SHA256-PAYLOAD\n" + r.amzDate + "\n" + r.scope + "\n" +
+		r.prevSig + "\n" + emptySHA256 + "\n" + hexSHA256(data)
+	r.prevSig = hex.EncodeToString(hmacSHA256(r.signingKey, stringToSign))
+
+	fmt.Fprintf(&r.out, "%x;chunk-signature=%s\r\n", len(data), r.prevSig)
+	r.out.Write(data)
+	r.out.WriteString("\r\n")
+}
+
+func (r *chunkSigningReader) Close() error {
+	return r.body.Close()
+}
+
+func hmacSHA256(key []byte, content string) []byte {
+	mac := hmac.New(sha256.New, key)
+	mac.Write([]byte(content))
+	return mac.Sum(nil)
+}
+
+func hexSHA256(content []byte) string {
+	sum := sha256.Sum256(content)
+	return hex.EncodeToString(sum[:])
+}
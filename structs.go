@@ -1,6 +1,9 @@
 package radosgwapi
 
-import "encoding/xml"
+import (
+	"encoding/xml"
+	"time"
+)
 
 type InitiateMultipartUploadResult struct {
 	XMLName  xml.Name `xml:"InitiateMultipartUploadResult"`
@@ -8,3 +11,403 @@ type InitiateMultipartUploadResult struct {
 	Key      string   `xml:"Key"`
 	UploadId string   `xml:"UploadId"`
 }
+
+type UserInfo struct {
+	Tenant           string        `json:"tenant"`
+	UserId           string        `json:"user_id"`
+	DisplayName      string        `json:"display_name"`
+	Email            string        `json:"email"`
+	Suspended        int           `json:"suspended"`
+	MaxBuckets       int           `json:"max_buckets"`
+	Subusers         []SubuserInfo `json:"subusers"`
+	Keys             []UserKey     `json:"keys"`
+	SwiftKeys        []SwiftKey    `json:"swift_keys"`
+	Caps             []UserCap     `json:"caps"`
+	OpMask           string        `json:"op_mask"`
+	DefaultPlacement string        `json:"default_placement"`
+	PlacementTags    []string      `json:"placement_tags"`
+	BucketQuota      Quota         `json:"bucket_quota"`
+	UserQuota        Quota         `json:"user_quota"`
+	Type             string        `json:"type"`
+	Stats            *StorageStats `json:"stats,omitempty"`
+}
+
+type SubuserInfo struct {
+	Id          string `json:"id"`
+	Permissions string `json:"permissions"`
+}
+
+type UserKey struct {
+	User      string `json:"user"`
+	AccessKey string `json:"access_key"`
+	SecretKey string `json:"secret_key"`
+}
+
+type SwiftKey struct {
+	User      string `json:"user"`
+	SecretKey string `json:"secret_key"`
+}
+
+type UserCap struct {
+	Type string `json:"type"`
+	Perm string `json:"perm"`
+}
+
+type Quota struct {
+	Enabled    bool  `json:"enabled"`
+	CheckOnRaw bool  `json:"check_on_raw"`
+	MaxSize    int64 `json:"max_size"`
+	MaxSizeKb  int64 `json:"max_size_kb"`
+	MaxObjects int64 `json:"max_objects"`
+}
+
+type StorageStats struct {
+	Size           int64 `json:"size"`
+	SizeActual     int64 `json:"size_actual"`
+	SizeUtilized   int64 `json:"size_utilized"`
+	SizeKb         int64 `json:"size_kb"`
+	SizeKbActual   int64 `json:"size_kb_actual"`
+	SizeKbUtilized int64 `json:"size_kb_utilized"`
+	NumObjects     int64 `json:"num_objects"`
+}
+
+type Usage struct {
+	Entries []UsageEntry   `json:"entries"`
+	Summary []UsageSummary `json:"summary"`
+}
+
+type UsageEntry struct {
+	User    string        `json:"user"`
+	Buckets []UsageBucket `json:"buckets"`
+}
+
+type UsageBucket struct {
+	Bucket     string          `json:"bucket"`
+	Time       string          `json:"time"`
+	Epoch      int64           `json:"epoch"`
+	Owner      string          `json:"owner"`
+	Categories []UsageCategory `json:"categories"`
+}
+
+type UsageCategory struct {
+	Category string `json:"category"`
+	UsageCounters
+}
+
+type UsageSummary struct {
+	User       string          `json:"user"`
+	Categories []UsageCategory `json:"categories"`
+	Total      UsageCounters   `json:"total"`
+}
+
+type UsageCounters struct {
+	BytesSent     int64 `json:"bytes_sent"`
+	BytesReceived int64 `json:"bytes_received"`
+	Ops           int64 `json:"ops"`
+	SuccessfulOps int64 `json:"successful_ops"`
+}
+
+type BucketStats struct {
+	Bucket        string                  `json:"bucket"`
+	NumShards     int                     `json:"num_shards"`
+	Tenant        string                  `json:"tenant"`
+	Zonegroup     string                  `json:"zonegroup"`
+	PlacementRule string                  `json:"placement_rule"`
+	Id            string                  `json:"id"`
+	Marker        string                  `json:"marker"`
+	IndexType     string                  `json:"index_type"`
+	Owner         string                  `json:"owner"`
+	Ver           string                  `json:"ver"`
+	MasterVer     string                  `json:"master_ver"`
+	Mtime         string                  `json:"mtime"`
+	CreationTime  string                  `json:"creation_time"`
+	MaxMarker     string                  `json:"max_marker"`
+	Usage         map[string]StorageStats `json:"usage"`
+	BucketQuota   Quota                   `json:"bucket_quota"`
+}
+
+type BucketIndexCheck struct {
+	InvalidMultipartEntries []string          `json:"invalid_multipart_entries"`
+	ExistingHeader          BucketIndexHeader `json:"existing_header"`
+	CalculatedHeader        BucketIndexHeader `json:"calculated_header"`
+}
+
+type BucketIndexHeader struct {
+	Usage map[string]StorageStats `json:"usage"`
+}
+
+type ListBucketResult struct {
+	XMLName               xml.Name       `xml:"ListBucketResult"`
+	Name                  string         `xml:"Name"`
+	Prefix                string         `xml:"Prefix"`
+	Delimiter             string         `xml:"Delimiter"`
+	MaxKeys               int            `xml:"MaxKeys"`
+	IsTruncated           bool           `xml:"IsTruncated"`
+	Marker                string         `xml:"Marker"`
+	NextMarker            string         `xml:"NextMarker"`
+	ContinuationToken     string         `xml:"ContinuationToken"`
+	NextContinuationToken string         `xml:"NextContinuationToken"`
+	StartAfter            string         `xml:"StartAfter"`
+	KeyCount              int            `xml:"KeyCount"`
+	Contents              []ObjectInfo   `xml:"Contents"`
+	CommonPrefixes        []CommonPrefix `xml:"CommonPrefixes"`
+}
+
+type ObjectInfo struct {
+	Key          string    `xml:"Key"`
+	LastModified time.Time `xml:"LastModified"`
+	ETag         string    `xml:"ETag"`
+	Size         int64     `xml:"Size"`
+	StorageClass string    `xml:"StorageClass"`
+	Owner        *Owner    `xml:"Owner"`
+}
+
+type Owner struct {
+	ID          string `xml:"ID"`
+	DisplayName string `xml:"DisplayName"`
+}
+
+type CommonPrefix struct {
+	Prefix string `xml:"Prefix"`
+}
+
+type ListAllMyBucketsResult struct {
+	XMLName xml.Name      `xml:"ListAllMyBucketsResult"`
+	Owner   Owner         `xml:"Owner"`
+	Buckets []BucketInfo  `xml:"Buckets>Bucket"`
+	Stats   *AccountStats `xml:"-"`
+}
+
+type BucketInfo struct {
+	Name         string    `xml:"Name"`
+	CreationDate time.Time `xml:"CreationDate"`
+}
+
+type AccountStats struct {
+	ObjectCount int64
+	BytesUsed   int64
+	MaxBuckets  int64
+}
+
+type BucketMetadata struct {
+	Region      string
+	ObjectCount int64
+	BytesUsed   int64
+}
+
+type DeleteObjectResult struct {
+	DeleteMarker bool
+	VersionId    string
+}
+
+type ObjectIdentifier struct {
+	Key       string `xml:"Key"`
+	VersionId string `xml:"VersionId,omitempty"`
+}
+
+type DeleteRequest struct {
+	XMLName xml.Name           `xml:"Delete"`
+	Quiet   bool               `xml:"Quiet"`
+	Objects []ObjectIdentifier `xml:"Object"`
+}
+
+type DeleteResult struct {
+	XMLName xml.Name        `xml:"DeleteResult"`
+	Deleted []DeletedObject `xml:"Deleted"`
+	Errors  []DeleteError   `xml:"Error"`
+}
+
+type DeletedObject struct {
+	Key                   string `xml:"Key"`
+	VersionId             string `xml:"VersionId"`
+	DeleteMarker          bool   `xml:"DeleteMarker"`
+	DeleteMarkerVersionId string `xml:"DeleteMarkerVersionId"`
+}
+
+type DeleteError struct {
+	Key       string `xml:"Key"`
+	VersionId string `xml:"VersionId"`
+	Code      string `xml:"Code"`
+	Message   string `xml:"Message"`
+}
+
+type CopyObjectResult struct {
+	XMLName      xml.Name  `xml:"CopyObjectResult"`
+	ETag         string    `xml:"ETag"`
+	LastModified time.Time `xml:"LastModified"`
+
+	VersionId       string `xml:"-"`
+	SourceVersionId string `xml:"-"`
+}
+
+type CopyPartResult struct {
+	XMLName      xml.Name  `xml:"CopyPartResult"`
+	ETag         string    `xml:"ETag"`
+	LastModified time.Time `xml:"LastModified"`
+}
+
+type CompletedPart struct {
+	PartNumber int    `xml:"PartNumber"`
+	ETag       string `xml:"ETag"`
+}
+
+type CompleteMultipartUpload struct {
+	XMLName xml.Name        `xml:"CompleteMultipartUpload"`
+	Parts   []CompletedPart `xml:"Part"`
+}
+
+type CompleteMultipartUploadResult struct {
+	XMLName  xml.Name `xml:"CompleteMultipartUploadResult"`
+	Location string   `xml:"Location"`
+	Bucket   string   `xml:"Bucket"`
+	Key      string   `xml:"Key"`
+	ETag     string   `xml:"ETag"`
+}
+
+type ListPartsResult struct {
+	XMLName              xml.Name `xml:"ListPartsResult"`
+	Bucket               string   `xml:"Bucket"`
+	Key                  string   `xml:"Key"`
+	UploadId             string   `xml:"UploadId"`
+	StorageClass         string   `xml:"StorageClass"`
+	PartNumberMarker     int      `xml:"PartNumberMarker"`
+	NextPartNumberMarker int      `xml:"NextPartNumberMarker"`
+	MaxParts             int      `xml:"MaxParts"`
+	IsTruncated          bool     `xml:"IsTruncated"`
+	Owner                Owner    `xml:"Owner"`
+	Initiator            Owner    `xml:"Initiator"`
+	Parts                []Part   `xml:"Part"`
+}
+
+type Part struct {
+	PartNumber   int       `xml:"PartNumber"`
+	LastModified time.Time `xml:"LastModified"`
+	ETag         string    `xml:"ETag"`
+	Size         int64     `xml:"Size"`
+}
+
+type ListMultipartUploadsResult struct {
+	XMLName            xml.Name          `xml:"ListMultipartUploadsResult"`
+	Bucket             string            `xml:"Bucket"`
+	KeyMarker          string            `xml:"KeyMarker"`
+	UploadIdMarker     string            `xml:"UploadIdMarker"`
+	NextKeyMarker      string            `xml:"NextKeyMarker"`
+	NextUploadIdMarker string            `xml:"NextUploadIdMarker"`
+	Prefix             string            `xml:"Prefix"`
+	Delimiter          string            `xml:"Delimiter"`
+	MaxUploads         int               `xml:"MaxUploads"`
+	IsTruncated        bool              `xml:"IsTruncated"`
+	Uploads            []MultipartUpload `xml:"Upload"`
+	CommonPrefixes     []CommonPrefix    `xml:"CommonPrefixes"`
+}
+
+type MultipartUpload struct {
+	Key          string    `xml:"Key"`
+	UploadId     string    `xml:"UploadId"`
+	Initiator    Owner     `xml:"Initiator"`
+	Owner        Owner     `xml:"Owner"`
+	StorageClass string    `xml:"StorageClass"`
+	Initiated    time.Time `xml:"Initiated"`
+}
+
+type VersioningConfiguration struct {
+	XMLName   xml.Name `xml:"VersioningConfiguration"`
+	Status    string   `xml:"Status,omitempty"`
+	MfaDelete string   `xml:"MfaDelete,omitempty"`
+}
+
+type ListVersionsResult struct {
+	XMLName             xml.Name `xml:"ListVersionsResult"`
+	Name                string   `xml:"Name"`
+	Prefix              string   `xml:"Prefix"`
+	Delimiter           string   `xml:"Delimiter"`
+	KeyMarker           string   `xml:"KeyMarker"`
+	VersionIdMarker     string   `xml:"VersionIdMarker"`
+	NextKeyMarker       string   `xml:"NextKeyMarker"`
+	NextVersionIdMarker string   `xml:"NextVersionIdMarker"`
+	MaxKeys             int      `xml:"MaxKeys"`
+	IsTruncated         bool     `xml:"IsTruncated"`
+	// Versions holds both the Version and the DeleteMarker entries, in
+	// listing order.
+	Versions       []ObjectVersion `xml:",any"`
+	CommonPrefixes []CommonPrefix  `xml:"CommonPrefixes"`
+}
+
+type ObjectVersion struct {
+	XMLName      xml.Name
+	Key          string    `xml:"Key"`
+	VersionId    string    `xml:"VersionId"`
+	IsLatest     bool      `xml:"IsLatest"`
+	LastModified time.Time `xml:"LastModified"`
+	ETag         string    `xml:"ETag"`
+	Size         int64     `xml:"Size"`
+	StorageClass string    `xml:"StorageClass"`
+	Owner        Owner     `xml:"Owner"`
+}
+
+type LifecycleConfiguration struct {
+	XMLName xml.Name        `xml:"LifecycleConfiguration"`
+	Rules   []LifecycleRule `xml:"Rule"`
+}
+
+type LifecycleRule struct {
+	ID     string           `xml:"ID,omitempty"`
+	Status string           `xml:"Status"`
+	Filter *LifecycleFilter `xml:"Filter,omitempty"`
+	// Prefix is the filter of rules written before Filter existed.
+	Prefix string `xml:"Prefix,omitempty"`
+
+	Expiration                     *LifecycleExpiration            `xml:"Expiration,omitempty"`
+	NoncurrentVersionExpiration    *NoncurrentVersionExpiration    `xml:"NoncurrentVersionExpiration,omitempty"`
+	Transitions                    []LifecycleTransition           `xml:"Transition"`
+	NoncurrentVersionTransitions   []NoncurrentVersionTransition   `xml:"NoncurrentVersionTransition"`
+	AbortIncompleteMultipartUpload *AbortIncompleteMultipartUpload `xml:"AbortIncompleteMultipartUpload,omitempty"`
+}
+
+// LifecycleFilter selects objects by Prefix, by Tag, or by all the
+// conditions of And. An empty filter selects every object.
+type LifecycleFilter struct {
+	Prefix string        `xml:"Prefix,omitempty"`
+	Tag    *Tag          `xml:"Tag,omitempty"`
+	And    *LifecycleAnd `xml:"And,omitempty"`
+}
+
+type LifecycleAnd struct {
+	Prefix string `xml:"Prefix,omitempty"`
+	Tags   []Tag  `xml:"Tag"`
+}
+
+type Tag struct {
+	Key   string `xml:"Key"`
+	Value string `xml:"Value"`
+}
+
+// LifecycleExpiration expires current versions after Days or at Date, which
+// must be midnight UTC. ExpiredObjectDeleteMarker instead removes delete
+// markers left without noncurrent versions.
+type LifecycleExpiration struct {
+	Days                      int        `xml:"Days,omitempty"`
+	Date                      *time.Time `xml:"Date,omitempty"`
+	ExpiredObjectDeleteMarker bool       `xml:"ExpiredObjectDeleteMarker,omitempty"`
+}
+
+type NoncurrentVersionExpiration struct {
+	NoncurrentDays int `xml:"NoncurrentDays"`
+}
+
+// LifecycleTransition moves objects to an RGW storage class, as defined in
+// the zonegroup placement targets, after Days or at Date.
+type LifecycleTransition struct {
+	Days         int        `xml:"Days,omitempty"`
+	Date         *time.Time `xml:"Date,omitempty"`
+	StorageClass string     `xml:"StorageClass"`
+}
+
+type NoncurrentVersionTransition struct {
+	NoncurrentDays int    `xml:"NoncurrentDays"`
+	StorageClass   string `xml:"StorageClass"`
+}
+
+type AbortIncompleteMultipartUpload struct {
+	DaysAfterInitiation int `xml:"DaysAfterInitiation"`
+}